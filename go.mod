@@ -17,6 +17,8 @@ require (
 	github.com/swaggo/gin-swagger v1.4.1
 	github.com/swaggo/swag v1.8.1
 	github.com/tidwall/gjson v1.14.4
+	golang.org/x/crypto v0.3.0
+	xorm.io/builder v0.3.9
 	xorm.io/xorm v1.3.0
 )
 
@@ -66,7 +68,6 @@ require (
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/ugorji/go/codec v1.1.7 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
-	golang.org/x/crypto v0.3.0 // indirect
 	golang.org/x/exp v0.0.0-20221205204356-47842c84f3db // indirect
 	golang.org/x/mod v0.7.0 // indirect
 	golang.org/x/net v0.3.0 // indirect
@@ -76,5 +77,4 @@ require (
 	google.golang.org/protobuf v1.28.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	rogchap.com/v8go v0.7.0 // indirect
-	xorm.io/builder v0.3.9 // indirect
 )
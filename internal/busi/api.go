@@ -6,6 +6,7 @@ import (
 	"time"
 
 	v1 "api-server/internal/busi/api/v1"
+	"api-server/internal/busi/core"
 	"api-server/pkg/models/busi"
 	"api-server/pkg/utils"
 
@@ -19,15 +20,19 @@ func registerV1(r *gin.Engine) {
 	apiv1 := r.Group("/api/v1")
 	{
 		apiv1.GET("/ping", v1.Ping)
+		apiv1.GET("/ready", v1.Ready)
 
 		{
-			apiv1.GET("/contracts", v1.ListContracts)                          // list contracts
-			apiv1.GET("/contract/:address", v1.GetContract)                    // contract detail
-			apiv1.GET("/contract/:address/txns", v1.ListContractTXNs)          // list contract's txns
-			apiv1.GET("/contract/:address/internal_txns", v1.ListInternalTXNs) // list contract's internal txns
-			apiv1.GET("/contract/:address/is_verify", v1.ContractIsVerify)     // contract is verify
-			apiv1.GET("/contract/:address/is_contract", v1.ContractIsContract) // contract is contract or address
-			apiv1.GET("/contract/:address/events", v1.ListContractEvents)      // contract is verify
+			apiv1.GET("/contracts", v1.ListContracts)                              // list contracts
+			apiv1.GET("/contract/:address", v1.GetContract)                        // contract detail
+			apiv1.GET("/contract/:address/txns", v1.ListContractTXNs)              // list contract's txns
+			apiv1.GET("/contract/:address/internal_txns", v1.ListInternalTXNs)     // list contract's internal txns
+			apiv1.GET("/contract/:address/is_verify", v1.ContractIsVerify)         // contract is verify
+			apiv1.GET("/contract/:address/is_contract", v1.ContractIsContract)     // contract is contract or address
+			apiv1.GET("/contract/:address/events", v1.ListContractEvents)          // list contract's event logs
+			apiv1.GET("/contract/:address/stats", v1.GetContractStats)             // contract's daily analytics
+			apiv1.GET("/contract/:address/history", v1.GetContractHistory)         // contract's bytecode and balance changes
+			apiv1.GET("/contract/:address/export/events", v1.ExportContractEvents) // export contract's event logs
 		}
 
 		{
@@ -35,10 +40,18 @@ func registerV1(r *gin.Engine) {
 			apiv1.GET("/txn/:txnHash", v1.GetTXN)
 			apiv1.GET("/txn/:txnHash/events", v1.ListTxnEvents)
 			apiv1.GET("/txn/:txnHash/internal_txns", v1.ListTxnInternalTXNs)
+			apiv1.GET("/txn/:txnHash/trace", v1.GetTxnTrace) // internal calls as a tree
 		}
 
 		{
-			apiv1.GET("/block/:height", v1.GetBlock)
+			apiv1.GET("/logs", v1.ListLogs) // event logs, like eth_getLogs
+		}
+
+		{
+			apiv1.GET("/blocks", v1.ListBlocks)                // list latest blocks
+			apiv1.GET("/block/:height", v1.GetBlock)           // block detail, null rounds included
+			apiv1.GET("/block/:height/txns", v1.ListBlockTXNs) // list block's txns
+			apiv1.GET("/block/hash/:hash", v1.GetBlockByHash)  // block detail by hash
 		}
 
 		{
@@ -49,17 +62,43 @@ func registerV1(r *gin.Engine) {
 
 		{
 			apiv1.GET("/address/:address", v1.GetAddress)
-			apiv1.GET("/address/:address/txns", v1.ListAddressTXNs)                  // list address's txns
-			apiv1.GET("/address/:address/internal_txns", v1.ListAddressInternalTXNs) // list address's internal txns
+			apiv1.GET("/address/:address/txns", v1.ListAddressTXNs)                               // list address's txns
+			apiv1.GET("/address/:address/internal_txns", v1.ListAddressInternalTXNs)              // list address's internal txns
+			apiv1.GET("/address/:address/balance", v1.GetAddressBalance)                          // address's balance as of a height
+			apiv1.GET("/address/:address/balance_history", v1.GetBalanceHistory)                  // address's sampled balances
+			apiv1.GET("/address/:address/export/txns", v1.ExportAddressTXNs)                      // export address's txns
+			apiv1.GET("/address/:address/export/internal_txns", v1.ExportAddressInternalTXNs)     // export address's internal txns
+			apiv1.GET("/address/:address/export/token_transfers", v1.ExportAddressTokenTransfers) // export address's token transfers
 		}
 
 		{
+			apiv1.GET("/accounts/top", v1.ListTopAccounts)   // rich list
+			apiv1.GET("/deployers/top", v1.ListTopDeployers) // deployer leaderboard
+		}
+
+		{
+			apiv1.GET("/gas/tracker", v1.GasTracker)
+			apiv1.GET("/gas/history", v1.GasHistory)
+			apiv1.GET("/gas/consumers/top", v1.ListTopGasConsumers) // contracts ranked by gas used
+		}
+
+		{
+			apiv1.GET("/search", v1.Search) // ranked search candidates
 			apiv1.GET("/search/:text/type", v1.SearchTextType)
 		}
 
 		{
 			apiv1.GET("/stat/overview", v1.StatOverview)
+			apiv1.GET("/stat/status", v1.StatStatus)                   // stat and chain head freshness
 			apiv1.GET("/stat/breakdown", v1.ListStatContractBreakdown) // list contract breakdown
+			apiv1.GET("/stat/series/:metric", v1.StatSeries)
+			apiv1.GET("/stat/active-users", v1.StatActiveUsers)
+			apiv1.GET("/stat/contract-activity", v1.StatContractActivity)
+		}
+
+		debug := apiv1.Group("/debug", utils.AdminAuth(utils.CNF.APIServer.AdminToken))
+		{
+			debug.GET("/versions/:table/:key", v1.ListRowVersions) // list superseded row versions
 		}
 	}
 }
@@ -78,14 +117,19 @@ func initconfig(ctx context.Context, cf *utils.TomlConfig) {
 	}
 
 	utils.EngineGroup = utils.NewEngineGroup(ctx, &[]utils.EngineInfo{
-		{utils.TaskDB, cf.APIServer.DB, nil},
-		{utils.APIDB, cf.APIServer.BusiDB, busi.Tables},
-		{utils.StatDB, cf.APIServer.StatDB, nil},
+		{Key: utils.TaskDB, Schema: cf.APIServer.DB},
+		{Key: utils.APIDB, Schema: cf.APIServer.BusiDB, Tables: busi.Tables, Indexes: busi.APIDBIndexes},
+		{Key: utils.StatDB, Schema: cf.APIServer.StatDB},
 	})
 }
 
 func Start() {
-	initconfig(context.Background(), &utils.CNF)
+	ctx := context.Background()
+	initconfig(ctx, &utils.CNF)
+
+	core.StartChainHeadTracker(ctx, time.Duration(utils.CNF.APIServer.ChainHeadRefreshInterval)*time.Second)
+	core.StartContractMetricsAggregator(ctx)
+	core.StartIndexer(ctx, time.Duration(utils.CNF.APIServer.LogIndexInterval)*time.Second)
 
 	// if Flags.Mode == "prod" {
 	gin.SetMode(gin.ReleaseMode)
@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -32,7 +35,7 @@ func ListContracts(c *gin.Context) {
 		return
 	}
 
-	if err := r.ListValidate(); err != nil {
+	if err := r.OffsetListValidate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
@@ -65,7 +68,7 @@ func ListStatContractBreakdown(c *gin.Context) {
 		return
 	}
 
-	if err := r.ListValidate(); err != nil {
+	if err := r.Validate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
@@ -85,20 +88,60 @@ func ListStatContractBreakdown(c *gin.Context) {
 // @Accept application/json,json
 // @Produce application/json,json
 // @Param address path string true "address"
-// @Success 200 {object} core.Contract
+// @Success 200 {object} core.ContractDetail
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
 // @Router /api/v1/contract/{address} [get]
 func GetContract(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	result, resp := core.GetContract(c.Request.Context(), address.EthAddress)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// GetContractHistory godoc
+// @Description List the bytecode and balance changes of a contract, and whether and when it was destroyed
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Param address path string true "address"
+// @Success 200 {object} core.ContractHistory
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/contract/{address}/history [get]
+func GetContractHistory(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ListQuery
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.OffsetListValidate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
 	}
 
-	result, resp := core.GetContract(c.Request.Context(), strings.ToLower(address))
+	result, resp := core.GetContractHistory(c.Request.Context(), address.EthAddress, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -112,7 +155,7 @@ func GetContract(c *gin.Context) {
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
-// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Param ListTxnsParams query core.ListTxnsParams true "ListTxnsParams"
 // @Param address path string true "address"
 // @Success 200 {object} core.TxnsList
 // @Failure 400 {object} utils.ResponseWithRequestId
@@ -120,25 +163,65 @@ func GetContract(c *gin.Context) {
 // @Router /api/v1/contract/{address}/txns [get]
 func ListContractTXNs(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ListTxnsParams
+	if err := c.ShouldBindQuery(&r); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
 	}
 
-	var r core.ListQuery
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListContractTXNs(c.Request.Context(), address.EthAddress, &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// GetContractStats godoc
+// @Description Get the daily analytics of a contract
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param StatRangeParams query core.StatRangeParams true "StatRangeParams"
+// @Param address path string true "address"
+// @Success 200 {object} core.ContractStats
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/contract/{address}/stats [get]
+func GetContractStats(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.StatRangeParams
 	if err := c.ShouldBindQuery(&r); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	if err := r.ListValidate(); err != nil {
+	if err := r.RangeValidate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	result, resp := core.ListContractTXNs(c.Request.Context(), strings.ToLower(address), &r)
+	result, resp := core.GetContractStats(c.Request.Context(), address.EthAddress, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -160,11 +243,11 @@ func ListContractTXNs(c *gin.Context) {
 // @Router /api/v1/contract/{address}/internal_txns [get]
 func ListInternalTXNs(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
 	}
 
 	var r core.ListQuery
@@ -178,7 +261,7 @@ func ListInternalTXNs(c *gin.Context) {
 		return
 	}
 
-	result, resp := core.ListInternalTXNs(c.Request.Context(), strings.ToLower(address), &r)
+	result, resp := core.ListInternalTXNs(c.Request.Context(), address.EthAddress, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -188,11 +271,11 @@ func ListInternalTXNs(c *gin.Context) {
 }
 
 // ListContractEvents godoc
-// @Description List contract's event
+// @Description List contract's event logs, filterable by event, topics and block range
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
-// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Param ListLogsParams query core.ListLogsParams true "ListLogsParams"
 // @Param address path string true "address"
 // @Success 200 {object} core.EventList
 // @Failure 400 {object} utils.ResponseWithRequestId
@@ -200,25 +283,68 @@ func ListInternalTXNs(c *gin.Context) {
 // @Router /api/v1/contract/{address}/events [get]
 func ListContractEvents(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ListLogsParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
 	}
 
-	var r core.ListQuery
+	result, resp := core.ListLogs(c.Request.Context(), address.EthAddress, &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListLogs godoc
+// @Description List event logs of every contract, like eth_getLogs
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param ListLogsParams query core.ListLogsParams true "ListLogsParams"
+// @Success 200 {object} core.EventList
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/logs [get]
+func ListLogs(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.ListLogsParams
 	if err := c.ShouldBindQuery(&r); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	if err := r.ListValidate(); err != nil {
+	if err := r.Validate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	result, resp := core.ListContractEvents(c.Request.Context(), strings.ToLower(address), &r)
+	var emitter string
+	if r.Address != "" {
+		address, resp := core.ResolveAddress(c.Request.Context(), r.Address)
+		if resp != nil {
+			app.HTTPResponse(resp.HttpCode, resp.Response)
+			return
+		}
+		emitter = address.EthAddress
+	}
+
+	result, resp := core.ListLogs(c.Request.Context(), emitter, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -240,11 +366,11 @@ func ListContractEvents(c *gin.Context) {
 // @Router /api/v1/contractverify/{address} [post]
 func SubmitContractVerify(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
 	}
 
 	var r core.SubmitContractVerifyRequest
@@ -258,7 +384,7 @@ func SubmitContractVerify(c *gin.Context) {
 		return
 	}
 
-	result, resp := core.SubmitContractVerify(c.Request.Context(), strings.ToLower(address), &r)
+	result, resp := core.SubmitContractVerify(c.Request.Context(), address.EthAddress, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -327,14 +453,14 @@ func ListCompileVersion(c *gin.Context) {
 // @Router /api/v1/contract/{address}/is_contract [get]
 func ContractIsContract(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
 	}
 
-	result, resp := core.GetContractIsContract(c.Request.Context(), strings.ToLower(address))
+	result, resp := core.GetContractIsContract(c.Request.Context(), address.EthAddress)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -355,14 +481,14 @@ func ContractIsContract(c *gin.Context) {
 // @Router /api/v1/contract/{address}/is_verify [get]
 func ContractIsVerify(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
 	}
 
-	result, resp := core.GetContractIsVerify(c.Request.Context(), strings.ToLower(address))
+	result, resp := core.GetContractIsVerify(c.Request.Context(), address.EthAddress)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -376,7 +502,7 @@ func ContractIsVerify(c *gin.Context) {
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
-// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Param ListTxnsParams query core.ListTxnsParams true "ListTxnsParams"
 // @Success 200 {object} nil
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
@@ -384,13 +510,13 @@ func ContractIsVerify(c *gin.Context) {
 func ListTXNs(c *gin.Context) {
 	app := utils.Gin{C: c}
 
-	var r core.ListQuery
+	var r core.ListTxnsParams
 	if err := c.ShouldBindQuery(&r); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	if err := r.ListValidate(); err != nil {
+	if err := r.Validate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
@@ -438,7 +564,7 @@ func GetTXN(c *gin.Context) {
 // @Accept application/json,json
 // @Produce application/json,json
 // @Param height path string true "height"
-// @Success 200 {object} busi.EVMBlockHeader
+// @Success 200 {object} core.Block
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
 // @Router /api/v1/block/{height} [get]
@@ -460,26 +586,31 @@ func GetBlock(c *gin.Context) {
 	app.HTTPResponseOK(result)
 }
 
-// GetAddress godoc
-// @Description Get evm address
+// ListBlocks godoc
+// @Description List latest blocks, null rounds included
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
-// @Param address path string true "address"
-// @Success 200 {object} busi.EVMAddress
+// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Success 200 {object} core.BlocksList
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
-// @Router /api/v1/address/{address} [get]
-func GetAddress(c *gin.Context) {
+// @Router /api/v1/blocks [get]
+func ListBlocks(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
+	var r core.ListQuery
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.ListValidate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
 	}
 
-	result, resp := core.GetAddress(c.Request.Context(), strings.ToLower(address))
+	result, resp := core.ListBlocks(c.Request.Context(), &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -488,38 +619,27 @@ func GetAddress(c *gin.Context) {
 	app.HTTPResponseOK(result)
 }
 
-// ListAddressTXNs godoc
-// @Description List address's transactions
+// GetBlockByHash godoc
+// @Description Get block detail by hash
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
-// @Param ListQuery query core.ListQuery true "ListQuery"
-// @Param address path string true "address"
-// @Success 200 {object} busi.EVMTransaction
+// @Param hash path string true "hash"
+// @Success 200 {object} core.Block
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
-// @Router /api/v1/contract/{address}/txns [get]
-func ListAddressTXNs(c *gin.Context) {
+// @Router /api/v1/block/hash/{hash} [get]
+func GetBlockByHash(c *gin.Context) {
 	app := utils.Gin{C: c}
 	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
-	}
-
-	var r core.ListQuery
-	if err := c.ShouldBindQuery(&r); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
-		return
-	}
-
-	if err := r.ListValidate(); err != nil {
+	hash := c.Param("hash")
+	if err := validate.Var(hash, "required"); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	result, resp := core.ListAddressTXNs(c.Request.Context(), strings.ToLower(address), &r)
+	result, resp := core.GetBlockByHash(c.Request.Context(), strings.ToLower(hash))
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -528,24 +648,25 @@ func ListAddressTXNs(c *gin.Context) {
 	app.HTTPResponseOK(result)
 }
 
-// ListAddressInternalTXNs godoc
-// @Description List address's internal transactions
+// ListBlockTXNs godoc
+// @Description List block's transactions
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
 // @Param ListQuery query core.ListQuery true "ListQuery"
-// @Param address path string true "address"
-// @Success 200 {object} busi.EVMInternalTX
+// @Param height path string true "height"
+// @Success 200 {object} core.TxnsList
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
-// @Router /api/v1/address/{address}/internal_txns [get]
-func ListAddressInternalTXNs(c *gin.Context) {
+// @Router /api/v1/block/{height}/txns [get]
+func ListBlockTXNs(c *gin.Context) {
 	app := utils.Gin{C: c}
 	validate := validator.New()
 
-	address := c.Param("address")
-	if err := validate.Var(address, "required"); err != nil {
+	height := c.Param("height")
+	if err := validate.Var(height, "required"); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
 	}
 
 	var r core.ListQuery
@@ -559,7 +680,7 @@ func ListAddressInternalTXNs(c *gin.Context) {
 		return
 	}
 
-	result, resp := core.ListInternalTXNs(c.Request.Context(), strings.ToLower(address), &r)
+	result, resp := core.ListBlockTXNs(c.Request.Context(), height, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -568,26 +689,26 @@ func ListAddressInternalTXNs(c *gin.Context) {
 	app.HTTPResponseOK(result)
 }
 
-// SearchTextType godoc
-// @Description Get transaction
+// GetAddress godoc
+// @Description Get evm address
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
-// @Param txnHash path string true "txnHash"
-// @Success 200 {object} core.EVMTransaction
+// @Param address path string true "address"
+// @Success 200 {object} core.Address
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
-// @Router /api/v1/search/{text}/type [get]
-func SearchTextType(c *gin.Context) {
+// @Router /api/v1/address/{address} [get]
+func GetAddress(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	text := c.Param("text")
-	if err := validate.Var(text, "required"); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
 	}
 
-	result, resp := core.GetSearchTextType(c.Request.Context(), strings.ToLower(text))
+	result, resp := core.GetAddress(c.Request.Context(), address.EthAddress)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -596,26 +717,38 @@ func SearchTextType(c *gin.Context) {
 	app.HTTPResponseOK(result)
 }
 
-// ListTxnEvents godoc
-// @Description List transaction's event
+// GetAddressBalance godoc
+// @Description Get the balance of an address as of a height, the latest by default
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
+// @Param BalanceAtParams query core.BalanceAtParams true "BalanceAtParams"
 // @Param address path string true "address"
-// @Success 200 {object} core.EventList
+// @Success 200 {object} core.AddressBalance
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
-// @Router /api/v1/txn/{txnHash}/events [get]
-func ListTxnEvents(c *gin.Context) {
+// @Router /api/v1/address/{address}/balance [get]
+func GetAddressBalance(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	txHash := c.Param("txnHash")
-	if err := validate.Var(txHash, "required"); err != nil {
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.BalanceAtParams
+	if err := c.ShouldBindQuery(&r); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
 	}
 
-	result, resp := core.ListTxnEvents(c.Request.Context(), strings.ToLower(txHash))
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.GetAddressBalance(c.Request.Context(), address.EthAddress, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -624,38 +757,38 @@ func ListTxnEvents(c *gin.Context) {
 	app.HTTPResponseOK(result)
 }
 
-// ListTxnInternalTXNs godoc
-// @Description List transaction's internal transactions
+// GetBalanceHistory godoc
+// @Description Get the balances of an address sampled over a height range, or a date range
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
-// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Param BalanceHistoryParams query core.BalanceHistoryParams true "BalanceHistoryParams"
 // @Param address path string true "address"
-// @Success 200 {object} core.InternalTxnsList
+// @Success 200 {object} core.BalanceHistory
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
-// @Router /api/v1/txn/{txnHash}/internal_txns [get]
-func ListTxnInternalTXNs(c *gin.Context) {
+// @Router /api/v1/address/{address}/balance_history [get]
+func GetBalanceHistory(c *gin.Context) {
 	app := utils.Gin{C: c}
-	validate := validator.New()
 
-	txHash := c.Param("txnHash")
-	if err := validate.Var(txHash, "required"); err != nil {
-		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
 	}
 
-	var r core.ListQuery
+	var r core.BalanceHistoryParams
 	if err := c.ShouldBindQuery(&r); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	if err := r.ListValidate(); err != nil {
+	if err := r.Validate(); err != nil {
 		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
 		return
 	}
 
-	result, resp := core.ListInternalTXNsByTxHash(c.Request.Context(), strings.ToLower(txHash), &r)
+	result, resp := core.GetBalanceHistory(c.Request.Context(), address.EthAddress, &r)
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
@@ -664,24 +797,702 @@ func ListTxnInternalTXNs(c *gin.Context) {
 	app.HTTPResponseOK(result)
 }
 
-// StatOverview godoc
-// @Description List transaction's internal transactions
+// ListAddressTXNs godoc
+// @Description List address's transactions
 // @Tags DATA-INFRA-API-External-V1
 // @Accept application/json,json
 // @Produce application/json,json
+// @Param ListTxnsParams query core.ListTxnsParams true "ListTxnsParams"
 // @Param address path string true "address"
-// @Success 200 {object} core.StatOverview
+// @Success 200 {object} busi.EVMTransaction
 // @Failure 400 {object} utils.ResponseWithRequestId
 // @Failure 500 {object} utils.ResponseWithRequestId
-// @Router /api/v1/stat/overview [get]
-func StatOverview(c *gin.Context) {
+// @Router /api/v1/contract/{address}/txns [get]
+func ListAddressTXNs(c *gin.Context) {
 	app := utils.Gin{C: c}
 
-	result, resp := core.GetStatOverview(c.Request.Context())
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
 	if resp != nil {
 		app.HTTPResponse(resp.HttpCode, resp.Response)
 		return
 	}
 
-	app.HTTPResponseOK(result)
+	var r core.ListTxnsParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListAddressTXNs(c.Request.Context(), address.EthAddress, &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListAddressInternalTXNs godoc
+// @Description List address's internal transactions
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Param address path string true "address"
+// @Success 200 {object} busi.EVMInternalTX
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/address/{address}/internal_txns [get]
+func ListAddressInternalTXNs(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ListQuery
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.ListValidate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListInternalTXNs(c.Request.Context(), address.EthAddress, &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// SearchTextType godoc
+// @Description Get transaction
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param txnHash path string true "txnHash"
+// @Success 200 {object} core.EVMTransaction
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/search/{text}/type [get]
+func SearchTextType(c *gin.Context) {
+	app := utils.Gin{C: c}
+	validate := validator.New()
+
+	text := c.Param("text")
+	if err := validate.Var(text, "required"); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	}
+
+	result, resp := core.GetSearchTextType(c.Request.Context(), text)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// Search godoc
+// @Description Search addresses, contracts, txns, blocks and labels, returns ranked candidates
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param SearchParams query core.SearchParams true "SearchParams"
+// @Success 200 {object} core.SearchResult
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/search [get]
+func Search(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.SearchParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.Search(c.Request.Context(), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListTxnEvents godoc
+// @Description List transaction's event
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param address path string true "address"
+// @Success 200 {object} core.EventList
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/txn/{txnHash}/events [get]
+func ListTxnEvents(c *gin.Context) {
+	app := utils.Gin{C: c}
+	validate := validator.New()
+
+	txHash := c.Param("txnHash")
+	if err := validate.Var(txHash, "required"); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	}
+
+	result, resp := core.ListTxnEvents(c.Request.Context(), strings.ToLower(txHash))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListTxnInternalTXNs godoc
+// @Description List transaction's internal transactions
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Param address path string true "address"
+// @Success 200 {object} core.InternalTxnsList
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/txn/{txnHash}/internal_txns [get]
+func ListTxnInternalTXNs(c *gin.Context) {
+	app := utils.Gin{C: c}
+	validate := validator.New()
+
+	txHash := c.Param("txnHash")
+	if err := validate.Var(txHash, "required"); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+	}
+
+	var r core.ListQuery
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.ListValidate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListInternalTXNsByTxHash(c.Request.Context(), strings.ToLower(txHash), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// GetTxnTrace godoc
+// @Description Get transaction's internal calls as a tree
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param txnHash path string true "txnHash"
+// @Success 200 {object} core.TxnTrace
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/txn/{txnHash}/trace [get]
+func GetTxnTrace(c *gin.Context) {
+	app := utils.Gin{C: c}
+	validate := validator.New()
+
+	txHash := c.Param("txnHash")
+	if err := validate.Var(txHash, "required"); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.GetTxnTrace(c.Request.Context(), strings.ToLower(txHash))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// StatOverview godoc
+// @Description List transaction's internal transactions
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param address path string true "address"
+// @Success 200 {object} core.StatOverview
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/stat/overview [get]
+func StatOverview(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	result, resp := core.GetStatOverview(c.Request.Context())
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// StatSeries godoc
+// @Description Get the history of a network statistic for charts
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param metric path string true "contract_count/internal_txn_count/external_txn_count/txn_count/deployer_address_count/network_address_count/tvl/tvl_usd"
+// @Param StatSeriesParams query core.StatSeriesParams true "StatSeriesParams"
+// @Success 200 {object} core.StatSeries
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/stat/series/{metric} [get]
+func StatSeries(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.StatSeriesParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.GetStatSeries(c.Request.Context(), c.Param("metric"), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// StatActiveUsers godoc
+// @Description Get the daily/weekly/monthly active users history and latest value
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param StatRangeParams query core.StatRangeParams true "StatRangeParams"
+// @Success 200 {object} core.StatActiveUsersHistory
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/stat/active-users [get]
+func StatActiveUsers(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.StatRangeParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.RangeValidate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.GetStatActiveUsers(c.Request.Context(), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// StatContractActivity godoc
+// @Description Get the new and active contracts history and latest value
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param StatRangeParams query core.StatRangeParams true "StatRangeParams"
+// @Success 200 {object} core.StatContractActivityHistory
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/stat/contract-activity [get]
+func StatContractActivity(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.StatRangeParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.RangeValidate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.GetStatContractActivity(c.Request.Context(), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListTopAccounts godoc
+// @Description List addresses and contracts ranked by balance
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Success 200 {object} core.TopAccountsList
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/accounts/top [get]
+func ListTopAccounts(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.ListQuery
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.OffsetListValidate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListTopAccounts(c.Request.Context(), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListTopDeployers godoc
+// @Description List addresses ranked by the number of contracts they created
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param ListQuery query core.ListQuery true "ListQuery"
+// @Success 200 {object} core.TopDeployersList
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/deployers/top [get]
+func ListTopDeployers(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.ListQuery
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.OffsetListValidate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListTopDeployers(c.Request.Context(), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// GasTracker godoc
+// @Description Get the current base fee, suggested priority fees and block utilization
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Success 200 {object} core.GasTracker
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/gas/tracker [get]
+func GasTracker(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	result, resp := core.GetGasTracker(c.Request.Context())
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// GasHistory godoc
+// @Description Get the base fee and utilization of a height range, or their daily averages of a date range
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param GasHistoryParams query core.GasHistoryParams true "GasHistoryParams"
+// @Success 200 {object} core.GasHistory
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/gas/history [get]
+func GasHistory(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.GasHistoryParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.GetGasHistory(c.Request.Context(), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListTopGasConsumers godoc
+// @Description List contracts ranked by the gas used by the txns calling them
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param ListTopGasConsumersParams query core.ListTopGasConsumersParams true "ListTopGasConsumersParams"
+// @Success 200 {object} core.GasConsumersList
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/gas/consumers/top [get]
+func ListTopGasConsumers(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	var r core.ListTopGasConsumersParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListTopGasConsumers(c.Request.Context(), &r)
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ListRowVersions godoc
+// @Description List every ingested version of a task_db row, superseded ones included, needs the admin token
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce application/json,json
+// @Param table path string true "evm_block_header/evm_transaction/evm_receipt/evm_internal_tx/evm_address/evm_contract"
+// @Param key path string true "height/hash/transaction_hash/parent_hash/address"
+// @Param X-Admin-Token header string true "admin token"
+// @Success 200 {object} core.RowVersions
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/debug/versions/{table}/{key} [get]
+func ListRowVersions(c *gin.Context) {
+	app := utils.Gin{C: c}
+	validate := validator.New()
+
+	table := c.Param("table")
+	key := c.Param("key")
+	if err := validate.Var(key, "required"); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	result, resp := core.ListRowVersions(c.Request.Context(), table, strings.ToLower(key))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// ExportAddressTXNs godoc
+// @Description Export the txns of an address as CSV or NDJSON, oldest first
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce text/csv,application/x-ndjson
+// @Param ExportParams query core.ExportParams true "ExportParams"
+// @Param address path string true "address"
+// @Success 200 {string} string
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/address/{address}/export/txns [get]
+func ExportAddressTXNs(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ExportParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	streamExport(c, address.EthAddress, "txns", &r, core.ExportAddressTXNs)
+}
+
+// ExportAddressInternalTXNs godoc
+// @Description Export the internal txns of an address as CSV or NDJSON, oldest first
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce text/csv,application/x-ndjson
+// @Param ExportParams query core.ExportParams true "ExportParams"
+// @Param address path string true "address"
+// @Success 200 {string} string
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/address/{address}/export/internal_txns [get]
+func ExportAddressInternalTXNs(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ExportParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	streamExport(c, address.EthAddress, "internal_txns", &r, core.ExportAddressInternalTXNs)
+}
+
+// ExportAddressTokenTransfers godoc
+// @Description Export the erc20/erc721 transfers of an address as CSV or NDJSON, oldest first
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce text/csv,application/x-ndjson
+// @Param ExportParams query core.ExportParams true "ExportParams"
+// @Param address path string true "address"
+// @Success 200 {string} string
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/address/{address}/export/token_transfers [get]
+func ExportAddressTokenTransfers(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ExportParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	streamExport(c, address.EthAddress, "token_transfers", &r, core.ExportAddressTokenTransfers)
+}
+
+// ExportContractEvents godoc
+// @Description Export the event logs of a contract as CSV or NDJSON, oldest first
+// @Tags DATA-INFRA-API-External-V1
+// @Accept application/json,json
+// @Produce text/csv,application/x-ndjson
+// @Param ExportParams query core.ExportParams true "ExportParams"
+// @Param address path string true "address"
+// @Success 200 {string} string
+// @Failure 400 {object} utils.ResponseWithRequestId
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/contract/{address}/export/events [get]
+func ExportContractEvents(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	address, resp := core.ResolveAddress(c.Request.Context(), c.Param("address"))
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	var r core.ExportParams
+	if err := c.ShouldBindQuery(&r); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	if err := r.Validate(); err != nil {
+		app.HTTPResponse(http.StatusOK, utils.NewResponse(utils.CodeBadRequest, err.Error(), nil))
+		return
+	}
+
+	streamExport(c, address.EthAddress, "events", &r, core.ExportContractEvents)
+}
+
+// streamExport streams an export as an attachment, errors are only responded while nothing was streamed yet.
+func streamExport(c *gin.Context, address, name string, r *core.ExportParams,
+	export func(context.Context, string, *core.ExportParams, io.Writer) *utils.BuErrorResponse) {
+	app := utils.Gin{C: c}
+
+	contentType := "text/csv; charset=utf-8"
+	if r.Format == core.ExportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_%s.%s"`, address, name, r.Format))
+
+	if resp := export(c.Request.Context(), address, r, c.Writer); resp != nil && !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+	}
 }
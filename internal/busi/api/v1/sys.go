@@ -1,6 +1,10 @@
 package v1
 
 import (
+	"net/http"
+	"strings"
+
+	"api-server/internal/busi/core"
 	"api-server/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -18,3 +22,48 @@ func Ping(c *gin.Context) {
 	app := utils.Gin{C: c}
 	app.HTTPResponseOK("pong")
 }
+
+// StatStatus godoc
+// @Description Get the freshness of the stat tables and the task_db chain head
+// @Tags Sys
+// @Accept application/json,json
+// @Produce application/json,json
+// @Success 200 {object} core.StatStatus
+// @Failure 500 {object} utils.ResponseWithRequestId
+// @Router /api/v1/stat/status [get]
+func StatStatus(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	result, resp := core.GetStatStatus(c.Request.Context())
+	if resp != nil {
+		app.HTTPResponse(resp.HttpCode, resp.Response)
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
+
+// Ready godoc
+// @Description Readiness examination, fails when ingestion of the task_db or stat tables stalls
+// @Tags Sys
+// @Accept application/json,json
+// @Produce application/json,json
+// @Success 200 {object} core.StatStatus
+// @Failure 503 {object} utils.ResponseWithRequestId
+// @Router /api/v1/ready [get]
+func Ready(c *gin.Context) {
+	app := utils.Gin{C: c}
+
+	result, resp := core.GetStatStatus(c.Request.Context())
+	if resp != nil {
+		app.HTTPResponse(http.StatusServiceUnavailable, resp.Response)
+		return
+	}
+	if !result.Ready {
+		app.HTTPResponse(http.StatusServiceUnavailable, utils.NewResponse(utils.CodeBlockExplorerAPIServerNotReadyErr,
+			strings.Join(result.Reasons, "; "), result))
+		return
+	}
+
+	app.HTTPResponseOK(result)
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ResolveAddress normalizes a path parameter address (0x, f410/t410, f0/t0, f1/f2/f3) to the lowercase 0x form
+// stored in task_db. ID addresses and non-delegated addresses of known actors are looked up by filecoin_address.
+func ResolveAddress(ctx context.Context, raw string) (*utils.Address, *utils.BuErrorResponse) {
+	address, err := utils.NormalizeAddress(raw)
+	if err != nil {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK,
+			Response: utils.NewResponse(utils.CodeBadRequest, err.Error(), nil)}
+	}
+
+	if address.ActorID == nil && address.EthAddress != "" {
+		return address, nil
+	}
+
+	// the actor may be known by its ID (or f1/f2/f3) address, prefer the address it's stored with
+	for _, table := range []string{"evm_address", "evm_contract"} {
+		result, err := canonicalSession(table).Cols("address").
+			Where("filecoin_address=?", address.FilecoinAddress).Limit(1).QueryString()
+		if err != nil {
+			log.Errorf("Execute sql error: %v", err)
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
+		if len(result) > 0 && result[0]["address"] != "" {
+			address.EthAddress = strings.ToLower(result[0]["address"])
+			return address, nil
+		}
+	}
+
+	if address.EthAddress == "" {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	return address, nil
+}
+
+// filecoinAddressOf returns the stored filecoin address, or derives the f410/f0 form from the 0x address.
+func filecoinAddressOf(ethAddress, filecoinAddress string) string {
+	if filecoinAddress != "" {
+		return filecoinAddress
+	}
+	address, err := utils.NormalizeAddress(ethAddress)
+	if err != nil {
+		return ""
+	}
+	return address.FilecoinAddress
+}
@@ -0,0 +1,476 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	"xorm.io/xorm"
+)
+
+const (
+	addressSummarySyncState = "address_summary"
+
+	// heights summarized in one transaction while catching up
+	addressSummaryBatchHeights = 2880
+	addressSummaryUpsertSize   = 500
+
+	// keccak256("Transfer(address,address,uint256)"), the same topic0 for erc20 and erc721
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+	tokenTypeERC20  = "erc20"
+	tokenTypeERC721 = "erc721"
+)
+
+// addressActivity the activity of an address in a range of heights.
+type addressActivity struct {
+	txnCount         int64
+	internalTxnCount int64
+	firstHeight      int64
+	lastHeight       int64
+	creator          string
+	creationTxn      string
+}
+
+func (a *addressActivity) seen(first, last int64) {
+	if a.firstHeight == 0 || (first > 0 && first < a.firstHeight) {
+		a.firstHeight = first
+	}
+	if last > a.lastHeight {
+		a.lastHeight = last
+	}
+}
+
+// summarizeAddresses adds the activity of the heights finalized since the last pass to the address summaries and
+// token holdings, up to the height the logs are indexed to.
+func summarizeAddresses(ctx context.Context, indexed int64) error {
+	head, err := chainHead.get()
+	if err != nil {
+		return err
+	}
+	to := head.Height - utils.CNF.APIServer.FinalityDepth
+	if indexed < to {
+		to = indexed
+	}
+
+	from, err := syncStateHeight(addressSummarySyncState)
+	if err != nil {
+		return err
+	}
+
+	for from < to {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		batchTo := from + addressSummaryBatchHeights
+		if batchTo > to {
+			batchTo = to
+		}
+		done, err := summarizeHeights(from, batchTo)
+		if err != nil || !done {
+			return err
+		}
+		from = batchTo
+	}
+
+	return nil
+}
+
+// syncStateHeight returns the height an indexer has processed up to, 0 before its first pass.
+func syncStateHeight(name string) (int64, error) {
+	state := new(busi.EVMSyncState)
+	b, err := utils.EngineGroup[utils.APIDB].Where("name = ?", name).Get(state)
+	if err != nil || !b {
+		return 0, err
+	}
+	return state.Height, nil
+}
+
+// summarizeHeights adds the activity of the heights in (from, to] to the summaries, atomically with the sync state.
+// Replicas take turns through an advisory lock, and the heights are only added while the sync state is still at
+// from; it's false when another replica holds the lock or already added them.
+func summarizeHeights(from, to int64) (bool, error) {
+	session := utils.EngineGroup[utils.APIDB].NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return false, err
+	}
+	defer session.Rollback()
+
+	locked, err := tryJobLock(session, addressSummarySyncState)
+	if err != nil || !locked {
+		return false, err
+	}
+	state := new(busi.EVMSyncState)
+	if _, err := session.Where("name = ?", addressSummarySyncState).Get(state); err != nil {
+		return false, err
+	}
+	if state.Height != from {
+		return false, nil
+	}
+
+	activities := make(map[string]*addressActivity)
+	activityOf := func(address string) *addressActivity {
+		address = strings.ToLower(address)
+		if activities[address] == nil {
+			activities[address] = &addressActivity{}
+		}
+		return activities[address]
+	}
+
+	for _, table := range []string{"evm_transaction", "evm_internal_tx"} {
+		rows, err := addressActivityRows(table, from, to)
+		if err != nil {
+			return false, err
+		}
+		for _, row := range rows {
+			activity := activityOf(row.Address)
+			if table == "evm_transaction" {
+				activity.txnCount += row.Count
+			} else {
+				activity.internalTxnCount += row.Count
+			}
+			activity.seen(row.First, row.Last)
+		}
+	}
+
+	var receipts []*busi.EVMReceipt
+	if err := canonicalSession("evm_receipt").Cols("height", "transaction_hash", "from", "contract_address").
+		Where(`height > ? and height <= ? and "to" = '' and contract_address != ''`, from, to).
+		Find(&receipts); err != nil {
+		return false, err
+	}
+	for _, receipt := range receipts {
+		activity := activityOf(receipt.ContractAddress)
+		activity.creator, activity.creationTxn = strings.ToLower(receipt.From), receipt.TransactionHash
+		activity.seen(receipt.Height, receipt.Height)
+	}
+
+	heights := make([]int64, 0, 2*len(activities))
+	for _, activity := range activities {
+		heights = append(heights, activity.firstHeight, activity.lastHeight)
+	}
+	timestamps, err := blockTimestamps(heights)
+	if err != nil {
+		return false, err
+	}
+
+	var logs []*busi.EVMLog
+	if err := utils.EngineGroup[utils.APIDB].Cols("address", "topic1", "topic2", "topic3", "data").
+		Where("height > ? and height <= ? and topic0 = ?", from, to, transferTopic).Find(&logs); err != nil {
+		return false, err
+	}
+	holdings, types := transferDeltas(logs), transferTokenTypes(logs)
+
+	if err := upsertAddressSummaries(session, activities, timestamps); err != nil {
+		return false, err
+	}
+	if err := upsertTokenHoldings(session, holdings, types); err != nil {
+		return false, err
+	}
+	if _, err := session.Exec(`insert into evm_sync_state (name, height, updated_at) values (?, ?, now())
+  on conflict (name) do update set height = excluded.height, updated_at = excluded.updated_at`,
+		addressSummarySyncState, to); err != nil {
+		return false, err
+	}
+
+	return true, session.Commit()
+}
+
+type addressActivityRow struct {
+	Address string
+	Count   int64
+	First   int64
+	Last    int64
+}
+
+// addressActivityRows counts the rows of table (txns or internal txns) each address sent or received in
+// (from, to], a row sent to self counting once.
+func addressActivityRows(table string, from, to int64) ([]*addressActivityRow, error) {
+	sql := fmt.Sprintf(`select address, count(*) as count, min(height) as first, max(height) as last from (
+  select "from" as address, height from %s where height > ? and height <= ? and %s
+  union all
+  select "to" as address, height from %s where height > ? and height <= ? and "to" != '' and "to" != "from" and %s
+) t group by address`, table, canonicalCond(table), table, canonicalCond(table))
+
+	var rows []*addressActivityRow
+	if err := utils.EngineGroup[utils.TaskDB].SQL(sql, from, to, from, to).Find(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// blockTimestamps returns the timestamps of the blocks at heights, null rounds left out.
+func blockTimestamps(heights []int64) (map[int64]int64, error) {
+	timestamps := make(map[int64]int64)
+	args := make([]interface{}, 0, len(heights))
+	for _, height := range heights {
+		if height > 0 {
+			args = append(args, height)
+		}
+	}
+	if len(args) == 0 {
+		return timestamps, nil
+	}
+
+	var headers []*busi.EVMBlockHeader
+	if err := canonicalSession("evm_block_header").Cols("height", "timestamp").In("height", args...).
+		Find(&headers); err != nil {
+		return nil, err
+	}
+	for _, header := range headers {
+		timestamps[header.Height] = header.Timestamp
+	}
+	return timestamps, nil
+}
+
+// transferDeltas sums the Transfer logs of tokens into the balance changes of their holders, by holder and token.
+// erc721 transfers have the token id as third topic and move one token.
+func transferDeltas(logs []*busi.EVMLog) map[[2]string]*big.Int {
+	deltas := make(map[[2]string]*big.Int)
+	add := func(holder, token string, amount *big.Int) {
+		if holder == "0x0000000000000000000000000000000000000000" {
+			return
+		}
+		key := [2]string{holder, token}
+		if deltas[key] == nil {
+			deltas[key] = new(big.Int)
+		}
+		deltas[key].Add(deltas[key], amount)
+	}
+
+	for _, l := range logs {
+		if len(l.Topic1) != 66 || len(l.Topic2) != 66 {
+			continue
+		}
+		amount := big.NewInt(1)
+		if l.Topic3 == "" {
+			amount = parseAttoFIL(l.Data)
+		}
+		token := strings.ToLower(l.Address)
+		add("0x"+l.Topic1[26:], token, new(big.Int).Neg(amount))
+		add("0x"+l.Topic2[26:], token, amount)
+	}
+
+	return deltas
+}
+
+// transferTokenTypes returns the type of the tokens of the Transfer logs, told by the token id topic of erc721.
+func transferTokenTypes(logs []*busi.EVMLog) map[string]string {
+	types := make(map[string]string)
+	for _, l := range logs {
+		tokenType := tokenTypeERC20
+		if l.Topic3 != "" {
+			tokenType = tokenTypeERC721
+		}
+		types[strings.ToLower(l.Address)] = tokenType
+	}
+	return types
+}
+
+func upsertAddressSummaries(session *xorm.Session, activities map[string]*addressActivity,
+	timestamps map[int64]int64) error {
+	const columns = 9
+	values := make([]string, 0, addressSummaryUpsertSize)
+	args := make([]interface{}, 0, addressSummaryUpsertSize*columns)
+
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		sql := `insert into evm_address_summary (address, txn_count, internal_txn_count, first_seen_height,
+  first_seen_time, last_seen_height, last_seen_time, creator, creation_txn, updated_at) values ` +
+			strings.Join(values, ", ") + ` on conflict (address) do update set
+  txn_count = evm_address_summary.txn_count + excluded.txn_count,
+  internal_txn_count = evm_address_summary.internal_txn_count + excluded.internal_txn_count,
+  first_seen_height = case when evm_address_summary.first_seen_height = 0 then excluded.first_seen_height
+    else evm_address_summary.first_seen_height end,
+  first_seen_time = case when evm_address_summary.first_seen_height = 0 then excluded.first_seen_time
+    else evm_address_summary.first_seen_time end,
+  last_seen_height = greatest(evm_address_summary.last_seen_height, excluded.last_seen_height),
+  last_seen_time = case when excluded.last_seen_height > evm_address_summary.last_seen_height
+    then excluded.last_seen_time else evm_address_summary.last_seen_time end,
+  creator = case when excluded.creator != '' then excluded.creator else evm_address_summary.creator end,
+  creation_txn = case when excluded.creation_txn != '' then excluded.creation_txn
+    else evm_address_summary.creation_txn end,
+  updated_at = excluded.updated_at`
+		if _, err := session.Exec(append([]interface{}{sql}, args...)...); err != nil {
+			return err
+		}
+		values, args = values[:0], args[:0]
+		return nil
+	}
+
+	for address, a := range activities {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, now())")
+		args = append(args, address, a.txnCount, a.internalTxnCount, a.firstHeight, timestamps[a.firstHeight],
+			a.lastHeight, timestamps[a.lastHeight], a.creator, a.creationTxn)
+		if len(values) == addressSummaryUpsertSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+func upsertTokenHoldings(session *xorm.Session, holdings map[[2]string]*big.Int, types map[string]string) error {
+	values := make([]string, 0, addressSummaryUpsertSize)
+	args := make([]interface{}, 0, addressSummaryUpsertSize*4)
+
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		sql := `insert into evm_token_holding (address, token, token_type, balance) values ` +
+			strings.Join(values, ", ") + ` on conflict (address, token) do update set
+  token_type = excluded.token_type, balance = evm_token_holding.balance + excluded.balance`
+		if _, err := session.Exec(append([]interface{}{sql}, args...)...); err != nil {
+			return err
+		}
+		values, args = values[:0], args[:0]
+		return nil
+	}
+
+	for key, delta := range holdings {
+		if delta.Sign() == 0 {
+			continue
+		}
+		values = append(values, "(?, ?, ?, ?::numeric)")
+		args = append(args, key[0], key[1], types[key[1]], delta.String())
+		if len(values) == addressSummaryUpsertSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+// getAddressSummary returns the summary of address: the finalized activity summarized by the indexer plus the
+// activity above the summarized height, counted on the fly.
+func getAddressSummary(address string) (*AddressSummary, error) {
+	height, err := syncStateHeight(addressSummarySyncState)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := new(busi.EVMAddressSummary)
+	if _, err := utils.EngineGroup[utils.APIDB].Where("address = ?", address).Get(stored); err != nil {
+		return nil, err
+	}
+	summary := &AddressSummary{
+		TxnCount:         stored.TxnCount,
+		InternalTxnCount: stored.InternalTxnCount,
+		FirstSeenHeight:  stored.FirstSeenHeight,
+		FirstSeenTime:    stored.FirstSeenTime,
+		LastSeenHeight:   stored.LastSeenHeight,
+		LastSeenTime:     stored.LastSeenTime,
+		Creator:          stored.Creator,
+		CreationTxn:      stored.CreationTxn,
+		SummarizedHeight: height,
+	}
+
+	// the recent heights
+	recent := &addressActivity{}
+	for _, table := range []string{"evm_transaction", "evm_internal_tx"} {
+		result, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`select count(*) as count,
+  coalesce(min(height), 0) as first, coalesce(max(height), 0) as last from %s
+  where height > ? and ("from" = ? or "to" = ?) and %s`, table, canonicalCond(table)),
+			height, address, address).QueryString()
+		if err != nil {
+			return nil, err
+		}
+		if len(result) == 0 {
+			continue
+		}
+		count, _ := strconv.ParseInt(result[0]["count"], 10, 64)
+		first, _ := strconv.ParseInt(result[0]["first"], 10, 64)
+		last, _ := strconv.ParseInt(result[0]["last"], 10, 64)
+		if table == "evm_transaction" {
+			recent.txnCount = count
+		} else {
+			recent.internalTxnCount = count
+		}
+		recent.seen(first, last)
+	}
+
+	summary.TxnCount += recent.txnCount
+	summary.InternalTxnCount += recent.internalTxnCount
+	if summary.FirstSeenHeight == 0 || summary.LastSeenHeight < recent.lastHeight {
+		timestamps, err := blockTimestamps([]int64{recent.firstHeight, recent.lastHeight})
+		if err != nil {
+			return nil, err
+		}
+		if summary.FirstSeenHeight == 0 && recent.firstHeight > 0 {
+			summary.FirstSeenHeight, summary.FirstSeenTime = recent.firstHeight, timestamps[recent.firstHeight]
+		}
+		if summary.LastSeenHeight < recent.lastHeight {
+			summary.LastSeenHeight, summary.LastSeenTime = recent.lastHeight, timestamps[recent.lastHeight]
+		}
+	}
+
+	return summary, nil
+}
+
+// getTokenHoldings returns the tokens held by address, the finalized balances plus the recent Transfer logs, sorted by
+// token.
+func getTokenHoldings(address string, summarizedHeight int64) ([]*TokenHolding, error) {
+	balances := make(map[string]*big.Int)
+	types := make(map[string]string)
+
+	var holdings []*busi.EVMTokenHolding
+	if err := utils.EngineGroup[utils.APIDB].Where("address = ?", address).Find(&holdings); err != nil {
+		return nil, err
+	}
+	for _, holding := range holdings {
+		balance, ok := new(big.Int).SetString(holding.Balance, 10)
+		if ok {
+			balances[holding.Token], types[holding.Token] = balance, holding.TokenType
+		}
+	}
+
+	topic := "0x000000000000000000000000" + strings.TrimPrefix(address, "0x")
+	var logs []*busi.EVMLog
+	if err := utils.EngineGroup[utils.APIDB].Cols("address", "topic1", "topic2", "topic3", "data").
+		Where("height > ? and topic0 = ? and (topic1 = ? or topic2 = ?)", summarizedHeight, transferTopic, topic,
+			topic).Find(&logs); err != nil {
+		return nil, err
+	}
+	for token, tokenType := range transferTokenTypes(logs) {
+		types[token] = tokenType
+	}
+	for key, delta := range transferDeltas(logs) {
+		if key[0] != address {
+			continue
+		}
+		if balances[key[1]] == nil {
+			balances[key[1]] = new(big.Int)
+		}
+		balances[key[1]].Add(balances[key[1]], delta)
+	}
+
+	tokenHoldings := make([]*TokenHolding, 0, len(balances))
+	for token, balance := range balances {
+		if balance.Sign() > 0 {
+			tokenHoldings = append(tokenHoldings, &TokenHolding{Token: token, TokenType: types[token],
+				Balance: balance.String()})
+		}
+	}
+	sort.Slice(tokenHoldings, func(i, j int) bool {
+		return tokenHoldings[i].Token < tokenHoldings[j].Token
+	})
+
+	return tokenHoldings, nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"testing"
+
+	"api-server/pkg/models/busi"
+)
+
+func TestTransferDeltas(t *testing.T) {
+	const (
+		token = "0x00000000000000000000000000000000000000aa"
+		alice = "0x00000000000000000000000000000000000000a1"
+		bob   = "0x00000000000000000000000000000000000000b0"
+		zero  = "0x0000000000000000000000000000000000000000"
+	)
+	topic := func(address string) string {
+		return "0x000000000000000000000000" + address[2:]
+	}
+
+	tests := []struct {
+		name string
+		logs []*busi.EVMLog
+		want map[[2]string]string
+	}{
+		{
+			name: "erc20 transfer",
+			logs: []*busi.EVMLog{{Address: token, Topic1: topic(alice), Topic2: topic(bob), Data: "0x64"}},
+			want: map[[2]string]string{{alice, token}: "-100", {bob, token}: "100"},
+		},
+		{
+			name: "transfers summed by holder",
+			logs: []*busi.EVMLog{
+				{Address: token, Topic1: topic(alice), Topic2: topic(bob), Data: "0x64"},
+				{Address: token, Topic1: topic(bob), Topic2: topic(alice), Data: "0x0a"},
+			},
+			want: map[[2]string]string{{alice, token}: "-90", {bob, token}: "90"},
+		},
+		{
+			name: "mint leaves the zero address out",
+			logs: []*busi.EVMLog{{Address: token, Topic1: topic(zero), Topic2: topic(bob), Data: "0x64"}},
+			want: map[[2]string]string{{bob, token}: "100"},
+		},
+		{
+			name: "erc721 moves one token",
+			logs: []*busi.EVMLog{{Address: token, Topic1: topic(alice), Topic2: topic(bob),
+				Topic3: "0x0000000000000000000000000000000000000000000000000000000000000007"}},
+			want: map[[2]string]string{{alice, token}: "-1", {bob, token}: "1"},
+		},
+		{
+			name: "token address lowercased",
+			logs: []*busi.EVMLog{{Address: "0x00000000000000000000000000000000000000AA", Topic1: topic(alice),
+				Topic2: topic(bob), Data: "0x01"}},
+			want: map[[2]string]string{{alice, token}: "-1", {bob, token}: "1"},
+		},
+		{
+			name: "malformed topics skipped",
+			logs: []*busi.EVMLog{{Address: token, Topic1: "0x01", Topic2: topic(bob), Data: "0x64"}},
+			want: map[[2]string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deltas := transferDeltas(tt.logs)
+			if len(deltas) != len(tt.want) {
+				t.Fatalf("deltas = %v, want %v", deltas, tt.want)
+			}
+			for key, want := range tt.want {
+				if got, ok := deltas[key]; !ok || got.String() != want {
+					t.Errorf("delta of %v = %v, want %s", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTransferTokenTypes(t *testing.T) {
+	const tokenID = "0x0000000000000000000000000000000000000000000000000000000000000007"
+
+	tests := []struct {
+		name string
+		logs []*busi.EVMLog
+		want map[string]string
+	}{
+		{
+			name: "erc20 has no token id",
+			logs: []*busi.EVMLog{{Address: "0xaa", Data: "0x64"}},
+			want: map[string]string{"0xaa": tokenTypeERC20},
+		},
+		{
+			name: "erc721 has a token id",
+			logs: []*busi.EVMLog{{Address: "0xbb", Topic3: tokenID}},
+			want: map[string]string{"0xbb": tokenTypeERC721},
+		},
+		{
+			name: "token address lowercased",
+			logs: []*busi.EVMLog{{Address: "0xAA", Data: "0x64"}, {Address: "0xBB", Topic3: tokenID}},
+			want: map[string]string{"0xaa": tokenTypeERC20, "0xbb": tokenTypeERC721},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types := transferTokenTypes(tt.logs)
+			if len(types) != len(tt.want) {
+				t.Fatalf("types = %v, want %v", types, tt.want)
+			}
+			for token, want := range tt.want {
+				if types[token] != want {
+					t.Errorf("type of %s = %q, want %q", token, types[token], want)
+				}
+			}
+		})
+	}
+}
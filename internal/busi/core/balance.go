@@ -0,0 +1,231 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetAddressBalance returns the balance of an address, or a contract, as of a height, the head by default.
+func GetAddressBalance(ctx context.Context, address string, r *BalanceAtParams) (interface{}, *utils.BuErrorResponse) {
+	table, err := balanceTableOf(address)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if table == "" {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusNotFound,
+			Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	head, err := chainHead.get()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	height := r.Height
+	if height == 0 || height > head.Height {
+		height = head.Height
+	}
+
+	points, err := sampleBalances(table, address, height, height, 1)
+	if err == nil {
+		err = fillBalanceUSD(points)
+	}
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return AddressBalance{Address: address, BalancePoint: *points[0]}, nil
+}
+
+// GetBalanceHistory samples the balance of an address, or a contract, at evenly spaced heights of a height range,
+// or of the heights of a date range, the last height always included.
+func GetBalanceHistory(ctx context.Context, address string, r *BalanceHistoryParams) (interface{},
+	*utils.BuErrorResponse) {
+	table, err := balanceTableOf(address)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if table == "" {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusNotFound,
+			Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	fromHeight, toHeight, err := balanceHistoryRange(r)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	history := BalanceHistory{Address: address, FromHeight: fromHeight, ToHeight: toHeight,
+		Points: make([]*BalancePoint, 0)}
+	if toHeight < fromHeight {
+		return history, nil
+	}
+
+	// a single point is the balance at the end of the range
+	sampleFrom, step := toHeight, int64(1)
+	if r.Points > 1 {
+		sampleFrom = fromHeight
+		if n := (toHeight - fromHeight + int64(r.Points) - 2) / int64(r.Points-1); n > 1 {
+			step = n
+		}
+	}
+
+	points, err := sampleBalances(table, address, sampleFrom, toHeight, step)
+	if err == nil {
+		err = fillBalanceUSD(points)
+	}
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	history.Points = points
+
+	return history, nil
+}
+
+// balanceHistoryRange returns the heights to sample, capped at the head.
+func balanceHistoryRange(r *BalanceHistoryParams) (int64, int64, error) {
+	head, err := chainHead.get()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fromHeight, toHeight := r.FromHeight, r.ToHeight
+	if toHeight == 0 {
+		from, _ := time.Parse(statDateLayout, r.From)
+		if fromHeight, err = heightBefore(from); err != nil {
+			return 0, 0, err
+		}
+		fromHeight++
+		if toHeight, err = statDateEndHeight(r.To); err != nil {
+			return 0, 0, err
+		}
+	}
+	if toHeight > head.Height {
+		toHeight = head.Height
+	}
+
+	return fromHeight, toHeight, nil
+}
+
+// balanceTableOf returns the table keeping the balance states of address, empty when it's unknown.
+func balanceTableOf(address string) (string, error) {
+	for _, table := range []string{"evm_address", "evm_contract"} {
+		exist, err := utils.EngineGroup[utils.TaskDB].Table(table).Where("address = ?", address).Exist()
+		if err != nil {
+			return "", err
+		}
+		if exist {
+			return table, nil
+		}
+	}
+
+	return "", nil
+}
+
+// sampleBalances returns the balance of address at every step heights from from to to, and at to. A state row is
+// only written when the address changes, so the balance at a height is the one of the latest state at or below it.
+func sampleBalances(table, address string, from, to, step int64) ([]*BalancePoint, error) {
+	rows, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select s.height, coalesce(b.height, 0) as state_height, coalesce(b.balance, '0') as balance,
+       coalesce(h.timestamp, 0) as timestamp
+from (select generate_series(?::bigint, ?::bigint, ?::bigint) union select ?::bigint) as s(height)
+    left join lateral (
+        select height, balance from %s
+        where address = ? and height <= s.height and %s
+        order by height desc limit 1) b on true
+    left join lateral (
+        select timestamp from evm_block_header
+        where height <= s.height and %s
+        order by height desc limit 1) h on true
+order by s.height`, table, canonicalCondBy(table, "address", "height"), canonicalCond("evm_block_header")),
+		from, to, step, to, address).QueryString()
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]*BalancePoint, 0, len(rows))
+	for _, row := range rows {
+		point := &BalancePoint{Balance: row["balance"]}
+		if point.Height, err = strconv.ParseInt(row["height"], 10, 64); err != nil {
+			return nil, err
+		}
+		if point.StateHeight, err = strconv.ParseInt(row["state_height"], 10, 64); err != nil {
+			return nil, err
+		}
+		if point.Timestamp, err = strconv.ParseInt(row["timestamp"], 10, 64); err != nil {
+			return nil, err
+		}
+		point.BalanceFIL = formatFIL(parseAttoFIL(point.Balance))
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// fillBalanceUSD converts the balances at the fil2usd rate of their day, or of the last day before it having one.
+func fillBalanceUSD(points []*BalancePoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	dateOf := func(point *BalancePoint) string {
+		return time.Unix(point.Timestamp, 0).UTC().Format(statDateLayout)
+	}
+	from, to := dateOf(points[0]), dateOf(points[len(points)-1])
+
+	rows, err := utils.EngineGroup[utils.StatDB].SQL(`
+select to_char(stat_date, 'YYYY-MM-DD') as date, fil2usd from fvm_total_value_locked_daily
+where stat_date <= ?
+  and stat_date >= coalesce((select max(stat_date) from fvm_total_value_locked_daily where stat_date <= ?), ?)
+order by stat_date`, to, from, from).QueryString()
+	if err != nil {
+		return err
+	}
+	dates := make([]string, 0, len(rows))
+	rates := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		rate, err := strconv.ParseFloat(row["fil2usd"], 64)
+		if err != nil {
+			return err
+		}
+		if _, ok := rates[row["date"]]; !ok {
+			dates = append(dates, row["date"])
+		}
+		rates[row["date"]] = rate
+	}
+
+	for _, point := range points {
+		date := dateOf(point)
+		i := sort.SearchStrings(dates, date)
+		if i == len(dates) || dates[i] != date {
+			i--
+		}
+		if i < 0 {
+			continue
+		}
+
+		fil := new(big.Float).Quo(new(big.Float).SetInt(parseAttoFIL(point.Balance)), attoFIL)
+		point.BalanceUSD, _ = fil.Mul(fil, big.NewFloat(rates[dates[i]])).Float64()
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+	"xorm.io/builder"
+)
+
+func GetBlock(ctx context.Context, heightStr string) (interface{}, *utils.BuErrorResponse) {
+	height, err := strconv.ParseInt(heightStr, 10, 64)
+	if err != nil || height < 0 {
+		log.Errorf("GetBlock ParseInt err: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+	}
+
+	block, buErr := getBlock("height = ?", height)
+	if buErr != nil {
+		return nil, buErr
+	}
+	if block != nil {
+		return block, nil
+	}
+
+	// epochs below the chain head without a block are null rounds
+	head, err := chainHead.get()
+	if err != nil {
+		log.Errorf("get chain head error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if height >= head.Height {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	return newNullRound(height, head), nil
+}
+
+func GetBlockByHash(ctx context.Context, hash string) (interface{}, *utils.BuErrorResponse) {
+	block, buErr := getBlock("hash = ?", hash)
+	if buErr != nil {
+		return nil, buErr
+	}
+	if block == nil {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	return block, nil
+}
+
+func getBlock(query interface{}, args ...interface{}) (*Block, *utils.BuErrorResponse) {
+	evmBlockHeader := new(busi.EVMBlockHeader)
+
+	b, err := canonicalSession("evm_block_header").Where(query, args...).Get(evmBlockHeader)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if !b {
+		return nil, nil
+	}
+
+	block := &Block{EVMBlockHeader: *evmBlockHeader}
+	block.ConfirmationBlocks, block.Finality, err = confirmationsOf(block.Height, true)
+	if err != nil {
+		log.Errorf("get chain head error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	block.TxnCount, err = canonicalSession("evm_transaction").Where("block_number = ?", block.Number).Count()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return block, nil
+}
+
+func newNullRound(height int64, head *ChainHead) *Block {
+	block := &Block{NullRound: true}
+	block.Height = height
+	block.Number = height
+	block.ConfirmationBlocks, block.Finality = head.confirmations(height)
+	return block
+}
+
+// ListBlocks lists the latest epochs, null rounds included, so pages are stable epoch ranges below the chain head.
+func ListBlocks(ctx context.Context, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+	var blocksList BlocksList
+
+	head, err := chainHead.get()
+	if err != nil {
+		log.Errorf("get chain head error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	blocksList.Hits, blocksList.Exact = head.Height+1, true
+	top := head.Height - int64(r.Offset)
+	if r.Cursor != "" {
+		c, err := decodeListCursor(r.Cursor, blockList, OrderDesc)
+		if err != nil {
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+		}
+		top = c.Height - 1
+		if c.Prev {
+			top = c.Height + int64(r.Limit)
+		}
+		if top > head.Height {
+			top = head.Height
+		}
+	}
+	if top < 0 {
+		return blocksList, nil
+	}
+	bottom := top - int64(r.Limit) + 1
+	if bottom < 0 {
+		bottom = 0
+	}
+
+	// the pages are epoch ranges, the cursors are their boundaries
+	if bottom > 0 {
+		blocksList.Next = (&listCursor{List: blockList, Order: OrderDesc, Height: bottom}).encode()
+	}
+	if top < head.Height {
+		blocksList.Prev = (&listCursor{List: blockList, Order: OrderDesc, Height: top, Prev: true}).encode()
+	}
+
+	evmBlockHeaders := make([]*busi.EVMBlockHeader, 0, r.Limit)
+	if err := canonicalSession("evm_block_header").Where("height between ? and ?", bottom, top).
+		Find(&evmBlockHeaders); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	numbers := make([]interface{}, 0, len(evmBlockHeaders))
+	headers := make(map[int64]*busi.EVMBlockHeader, len(evmBlockHeaders))
+	for _, evmBlockHeader := range evmBlockHeaders {
+		headers[evmBlockHeader.Height] = evmBlockHeader
+		numbers = append(numbers, evmBlockHeader.Number)
+	}
+
+	txnCounts, err := blockTxnCounts(numbers)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	blocks := make([]*Block, 0, top-bottom+1)
+	for height := top; height >= bottom; height-- {
+		evmBlockHeader, ok := headers[height]
+		if !ok {
+			blocks = append(blocks, newNullRound(height, head))
+			continue
+		}
+
+		block := &Block{EVMBlockHeader: *evmBlockHeader, TxnCount: txnCounts[evmBlockHeader.Number]}
+		block.ConfirmationBlocks, block.Finality = head.confirmations(height)
+		blocks = append(blocks, block)
+	}
+	blocksList.Blocks = blocks
+
+	return blocksList, nil
+}
+
+// blockTxnCounts counts the transactions of the given block numbers in one query.
+func blockTxnCounts(numbers []interface{}) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(numbers))
+	if len(numbers) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		BlockNumber int64
+		Count       int64
+	}
+	if err := canonicalSession("evm_transaction").
+		Select("block_number, count(*) as count").In("block_number", numbers...).
+		GroupBy("block_number").Find(&rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.BlockNumber] = row.Count
+	}
+
+	return counts, nil
+}
+
+func ListBlockTXNs(ctx context.Context, heightStr string, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+	var txnsList TxnsList
+
+	height, err := strconv.ParseInt(heightStr, 10, 64)
+	if err != nil || height < 0 {
+		log.Errorf("ListBlockTXNs ParseInt err: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+	}
+
+	// null rounds have no transactions
+	evmBlockHeader := new(busi.EVMBlockHeader)
+	b, err := canonicalSession("evm_block_header").Where("height = ?", height).Get(evmBlockHeader)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if !b {
+		return txnsList, nil
+	}
+
+	cond := builder.Eq{"block_number": evmBlockHeader.Number}
+	txnsList.Hits, err = evmTransactionCount(cond)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	txnsList.Exact = true
+	if txnsList.Hits <= 0 {
+		return txnsList, nil
+	}
+
+	transactions, next, prev, resp := evmTransactionFind(cond, r, OrderAsc)
+	if resp != nil {
+		return nil, resp
+	}
+	txns, err := enrichTXNs(transactions)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	txnsList.EVMTransaction, txnsList.Next, txnsList.Prev = txns, next, prev
+
+	return txnsList, nil
+}
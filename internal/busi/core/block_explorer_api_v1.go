@@ -7,12 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
+	"math/big"
 	"net/http"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
+	"time"
 
 	"api-server/pkg/models/busi"
 	"api-server/pkg/utils"
@@ -23,6 +23,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
+	"xorm.io/builder"
+	"xorm.io/xorm"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 )
@@ -53,68 +55,54 @@ func listContractsVerified(ctx context.Context, r *ListContractsParams) (interfa
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	contractsList.Hits = total
+	contractsList.Hits, contractsList.Exact = total, true
 	if contractsList.Hits <= 0 {
 		return contractsList, nil
 	}
 
 	// get contracts list
 	verifiedContracts := make([]*busi.EVMContractVerify, 0)
-	if err := utils.EngineGroup[utils.APIDB].Limit(r.Limit,
-		r.Offset).Desc("create_at").Find(&verifiedContracts); err != nil {
+	if err := utils.EngineGroup[utils.APIDB].Where("status = ?", busi.EVMContractVerifyStatusSuccessfully).
+		Limit(r.Limit, r.Offset).Desc("create_at").Find(&verifiedContracts); err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	contractsSlice := make([]*Contract, 0, len(verifiedContracts))
+	addresses := make([]string, 0, len(verifiedContracts))
+	verifyOf := make(map[string]*busi.EVMContractVerify, len(verifiedContracts))
 	for _, verifiedContract := range verifiedContracts {
-		var c Contract
-
-		c.Txns, err = evmTransactionCount(verifiedContract.Address)
-		if err != nil {
-			log.Error(err)
-			break
-		}
-		creatorTx, err := findCreatorTransaction(verifiedContract.Address)
-		if err != nil {
-			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-				Response: utils.ErrBlockExplorerAPIServerInternal}
-		}
-		if creatorTx != nil {
-			c.Txns += 1
-		}
+		addresses = append(addresses, verifiedContract.Address)
+		verifyOf[strings.ToLower(verifiedContract.Address)] = verifiedContract
+	}
 
-		var (
-			contractMeta busi.EVMContract
-		)
+	contractMetas := make([]*busi.EVMContract, 0, len(addresses))
+	if err := canonicalSession("evm_contract").In("address", addresses).Find(&contractMetas); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	metaOf := make(map[string]*busi.EVMContract, len(contractMetas))
+	for _, contractMeta := range contractMetas {
+		metaOf[strings.ToLower(contractMeta.Address)] = contractMeta
+	}
 
-		b, errT := utils.EngineGroup[utils.TaskDB].Where("address = ?", verifiedContract.Address).Get(&contractMeta)
-		if errT != nil {
-			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-				Response: utils.ErrBlockExplorerAPIServerInternal}
-		}
-		if !b {
+	contracts := make([]*busi.EVMContract, 0, len(verifiedContracts))
+	for _, verifiedContract := range verifiedContracts {
+		contractMeta, ok := metaOf[strings.ToLower(verifiedContract.Address)]
+		if !ok {
+			log.Errorf("verified contract %s not found", verifiedContract.Address)
 			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 				Response: utils.ErrBlockExplorerAPIServerInternal}
 		}
-
-		c.Height = contractMeta.Height
-		c.Address = verifiedContract.Address
-		c.FilecoinAddress = contractMeta.FilecoinAddress
-		c.Balance = contractMeta.Balance
-		c.Version = int64(contractMeta.Version)
-
-		c.Name = verifiedContract.ContractName
-		c.CompilerType = verifiedContract.CompilerType
-		c.CompilerVersion = verifiedContract.CompilerVersion
-		c.License = verifiedContract.LicenseType
-		c.Verified = verifiedContract.CreateAt
-
-		contractsSlice = append(contractsSlice, &c)
+		contracts = append(contracts, contractMeta)
 	}
 
-	contractsList.Contracts = contractsSlice
+	if contractsList.Contracts, err = newContracts(contracts, verifyOf); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
 
 	return contractsList, nil
 }
@@ -126,53 +114,87 @@ func listAllContracts(ctx context.Context, r *ListContractsParams) (interface{},
 	)
 
 	// get the numbers of contracts
-	total, err := busiTableRecordsCount(&c)
+	total, exact, err := tableHits(c.TableName())
 	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	contractsList.Hits = total
+	contractsList.Hits, contractsList.Exact = total, exact
 	if contractsList.Hits <= 0 {
 		return contractsList, nil
 	}
 
 	// get contracts list
 	contracts := make([]*busi.EVMContract, 0)
-	if err := busiSQLExecute(&r.ListQuery, &contracts); err != nil {
+	if err := busiSQLExecute(c.TableName(), &r.ListQuery, &contracts); err != nil {
 		return nil, err
 	}
 
+	addresses := make([]string, 0, len(contracts))
+	for _, contract := range contracts {
+		addresses = append(addresses, contract.Address)
+	}
+	verifyOf, err := successContractVerifies(ctx, addresses)
+	if err != nil {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	if contractsList.Contracts, err = newContracts(contracts, verifyOf); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return contractsList, nil
+}
+
+// newContracts returns the list rows of contracts with their verification if any, by lowercase address, counting
+// their txns and finding their creation txns in batches.
+func newContracts(contracts []*busi.EVMContract, verifyOf map[string]*busi.EVMContractVerify) ([]*Contract,
+	error) {
 	contractsSlice := make([]*Contract, 0, len(contracts))
+	if len(contracts) == 0 {
+		return contractsSlice, nil
+	}
+
+	addresses := make([]string, 0, len(contracts))
 	for _, contract := range contracts {
-		var c Contract
+		addresses = append(addresses, contract.Address)
+	}
 
-		c.Txns, err = evmTransactionCount(contract.Address)
-		if err != nil {
-			log.Error(err)
-			break
-		}
-		creatorTx, err := findCreatorTransaction(contract.Address)
-		if err != nil {
-			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-				Response: utils.ErrBlockExplorerAPIServerInternal}
+	txns, err := txnCounts.countAll(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	// the creation txn is sent to no address, it's not counted as a txn of the contract
+	created := make(map[string]bool, len(addresses))
+	receipts, err := canonicalSession("evm_receipt").Cols("contract_address").
+		Where(`"to" = ''`).In("contract_address", addresses).QueryString()
+	if err != nil {
+		return nil, err
+	}
+	for _, receipt := range receipts {
+		created[strings.ToLower(receipt["contract_address"])] = true
+	}
+
+	for _, contract := range contracts {
+		c := &Contract{
+			Height:          contract.Height,
+			Address:         contract.Address,
+			FilecoinAddress: contract.FilecoinAddress,
+			Balance:         contract.Balance,
+			Version:         int64(contract.Version),
+			Txns:            txns[contract.Address],
 		}
-		if creatorTx != nil {
+		if created[strings.ToLower(contract.Address)] {
 			c.Txns += 1
 		}
 
-		c.Height = contract.Height
-		c.Address = contract.Address
-		c.FilecoinAddress = contract.FilecoinAddress
-		c.Balance = contract.Balance
-		c.Version = int64(contract.Version)
-
-		contractVerify, err := GetSuccessContractVerifyByAddress(ctx, c.Address)
-		if err != nil {
-			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-				Response: utils.ErrBlockExplorerAPIServerInternal}
-		}
-		if contractVerify != nil {
+		if contractVerify := verifyOf[strings.ToLower(contract.Address)]; contractVerify != nil {
 			c.Name = contractVerify.ContractName
 			c.CompilerType = contractVerify.CompilerType
 			c.CompilerVersion = contractVerify.CompilerVersion
@@ -180,33 +202,23 @@ func listAllContracts(ctx context.Context, r *ListContractsParams) (interface{},
 			c.Verified = contractVerify.CreateAt
 		}
 
-		contractsSlice = append(contractsSlice, &c)
+		contractsSlice = append(contractsSlice, c)
 	}
 
-	contractsList.Contracts = contractsSlice
-
-	return contractsList, nil
+	return contractsSlice, nil
 }
 
 func GetContract(ctx context.Context, address string) (interface{}, *utils.BuErrorResponse) {
 	evmContract := new(busi.EVMContract)
 
-	b, err := utils.EngineGroup[utils.TaskDB].
-		Where("address=? or filecoin_address=?", address, address).
-		OrderBy("height desc").Get(evmContract)
+	b, err := canonicalSession("evm_contract").Where("address=?", address).Get(evmContract)
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	var ethAddress string
-	// if query address is filecoin address
-	if address[0] == 'f' || address[0] == 't' {
-		ethAddress = evmContract.Address
-	} else {
-		ethAddress = address
-	}
+	ethAddress := address
 
 	if !b {
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
@@ -220,12 +232,22 @@ func GetContract(ctx context.Context, address string) (interface{}, *utils.BuErr
 
 	contractDetail := ContractDetail{
 		Address:         ethcommon.HexToAddress(evmContract.Address).Hex(),
-		FilecoinAddress: evmContract.FilecoinAddress,
+		FilecoinAddress: filecoinAddressOf(evmContract.Address, evmContract.FilecoinAddress),
 		Balance:         evmContract.Balance,
 		Nonce:           evmContract.Nonce,
 		ByteCode:        evmContract.ByteCode,
 	}
 
+	if contractDetail.ContractDestruction, err = contractDestruction(evmContract); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	// a destroyed contract has no code left to show, the history keeps it
+	if contractDetail.Destroyed {
+		contractDetail.ByteCode = ""
+	}
+
 	transaction, err := findCreatorTransaction(ethAddress)
 	if err != nil {
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -294,50 +316,59 @@ func GetContract(ctx context.Context, address string) (interface{}, *utils.BuErr
 	return contractDetail, nil
 }
 
-func ListContractTXNs(ctx context.Context, address string, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+func ListContractTXNs(ctx context.Context, address string, r *ListTxnsParams) (interface{}, *utils.BuErrorResponse) {
 	var (
 		txnsList TxnsList
 	)
 
-	// if address is contract, must add creator hash
-	creatorTx, err := findCreatorTransaction(address)
-	if err != nil {
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
+	cond, resp := txnFilterCondOf(address, &r.TxnFilter)
+	if resp != nil {
+		return nil, resp
+	}
+
+	// if address is contract, must add creator hash, unless filtered out
+	var creatorTx *busi.EVMTransaction
+	if r.IsEmpty() {
+		var err error
+		if creatorTx, err = findCreatorTransaction(address); err != nil {
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
+		if creatorTx != nil {
+			cond = builder.Or(cond, builder.Eq{"hash": creatorTx.Hash})
+		}
 	}
 
 	// get the numbers of transactions
-	total, err := evmTransactionCount(address)
+	total, exact, err := txnHits(address, cond, r)
 	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
+	// the cached count of address doesn't include the creator tx
+	if creatorTx != nil && r.Cursor == "" {
+		total += 1
+	}
 
-	txnsList.Hits = total
-	if creatorTx == nil && txnsList.Hits <= 0 {
+	txnsList.Hits, txnsList.Exact = total, exact
+	if r.Cursor == "" && txnsList.Hits <= 0 {
 		return txnsList, nil
 	}
 
 	// get transactions list
-	transactions := make([]*busi.EVMTransaction, 0)
-	if err := evmTransactionFind(address, r, &transactions); err != nil {
-		return nil, err
-	}
-	// creator tx must at last
-	if creatorTx != nil && len(transactions) < r.Limit {
-		transactions = append(transactions, creatorTx)
-		txnsList.Hits += 1
+	transactions, next, prev, resp := evmTransactionFind(cond, &r.ListQuery, r.Order)
+	if resp != nil {
+		return nil, resp
 	}
 
-	for _, transaction := range transactions {
-		if transaction.To == "" {
-			transaction.MethodName = "create"
-		} else {
-			transaction.MethodName, transaction.MethodSig, transaction.Params = parseMethodAndParamsFromContract(transaction.Input,
-				address)
-		}
+	txns, err := enrichTXNs(transactions)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
-	txnsList.EVMTransaction = transactions
+	txnsList.EVMTransaction, txnsList.Next, txnsList.Prev = txns, next, prev
 
 	return txnsList, nil
 }
@@ -413,74 +444,42 @@ func parseEventsFromReceipt(receipt busi.EVMReceipt,
 	return events, nil
 }
 
-func ListContractEvents(ctx context.Context, address string, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
-	var (
-		evmReceipts []busi.EVMReceipt
-	)
-	err := utils.EngineGroup[utils.TaskDB].Where("`to`=? and logs!='[]'", address).
-		OrderBy("height desc").Limit(r.Limit).Find(&evmReceipts)
-	if err != nil {
-		log.Errorf("Execute sql error: %v", err)
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
-	}
-	var events []*Event
-	for _, receipt := range evmReceipts {
-		ets, buErr := parseEventsFromReceipt(receipt, address)
-		if buErr != nil {
-			log.Errorf("Execute sql error: %v", err)
-			return nil, buErr
-		}
-		events = append(events, ets...)
-	}
-	// 因为一个receipt可能有多个event, 只取r.limit个，参考etherscan
-	if len(events) > r.Limit {
-		events = events[:r.Limit]
-	}
-
-	return EventList{Events: events, Hits: len(events)}, nil
-}
-
+// ListInternalTXNs lists the internal txns of the txns from or to address, counted by the cached per address count.
 func ListInternalTXNs(ctx context.Context, address string, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
 	var (
 		internalTXNsList InternalTxnsList
 	)
 
-	count, err := utils.EngineGroup[utils.TaskDB].Table(new(busi.EVMInternalTX)).
-		Join("inner", "evm_transaction", "evm_internal_tx.parent_hash=evm_transaction.hash").
-		Where("evm_transaction.from=? or evm_transaction.to=?", address, address).Count()
-	if err != nil {
-		log.Errorf("Execute sql error: %v", err)
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
-	}
-
-	internalTXNsList.Hits = count
-	if internalTXNsList.Hits <= 0 {
-		return internalTXNsList, nil
-	}
+	if r.Cursor == "" || r.Total {
+		count, err := internalTxnCounts.count(address)
+		if err != nil {
+			log.Errorf("Execute sql error: %v", err)
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
 
-	internalTxs := make([]*busi.EVMInternalTX, 0)
-	err = utils.EngineGroup[utils.TaskDB].Select("evm_internal_tx.*").
-		Join("inner", "evm_transaction", "evm_internal_tx.parent_hash=evm_transaction.hash").
-		Where("evm_transaction.from=? or evm_transaction.to=?", address, address).
-		Limit(r.Limit, r.Offset).OrderBy("height desc").Find(&internalTxs)
-	if err != nil {
-		log.Errorf("Execute sql error: %v", err)
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
+		internalTXNsList.Hits, internalTXNsList.Exact = count, true
+		if internalTXNsList.Hits <= 0 {
+			return internalTXNsList, nil
+		}
 	}
 
-	internalTXNsList.EVMInternalTX = internalTxs
+	return findInternalTxnsPage(addressInternalTxnsSession(address).Select("evm_internal_tx.*"), r, internalTXNsList)
+}
 
-	return internalTXNsList, nil
+// addressInternalTxnsSession selects the canonical internal txns of the canonical txns from or to address.
+func addressInternalTxnsSession(address string) *xorm.Session {
+	return canonicalSession("evm_internal_tx").
+		Join("inner", "evm_transaction", "evm_internal_tx.parent_hash=evm_transaction.hash").
+		Where(canonicalCond("evm_transaction")).
+		And("(evm_transaction.from=? or evm_transaction.to=?)", address, address)
 }
 
 func SubmitContractVerify(ctx context.Context, address string, r *SubmitContractVerifyRequest) (interface{},
 	*utils.BuErrorResponse) {
 
 	var contract busi.EVMContract
-	exist, err := utils.EngineGroup[utils.TaskDB].Where("address=?", address).OrderBy("height desc").Get(&contract)
+	exist, err := canonicalSession("evm_contract").Where("address=?", address).Get(&contract)
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -722,6 +721,28 @@ func GetSuccessContractVerifyByAddress(ctx context.Context, address string) (*bu
 	return getContractVerifyByQuery(ctx, "address=? and status=?", address, busi.EVMContractVerifyStatusSuccessfully)
 }
 
+// successContractVerifies returns the successful verifications of addresses by lowercase address, the first one of an
+// address verified several times.
+func successContractVerifies(ctx context.Context, addresses []string) (map[string]*busi.EVMContractVerify, error) {
+	verifyOf := make(map[string]*busi.EVMContractVerify, len(addresses))
+	if len(addresses) == 0 {
+		return verifyOf, nil
+	}
+
+	var contractVerifies []*busi.EVMContractVerify
+	if err := utils.EngineGroup[utils.APIDB].Where("status = ?", busi.EVMContractVerifyStatusSuccessfully).
+		In("address", addresses).OrderBy("id").Find(&contractVerifies); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, err
+	}
+	for _, contractVerify := range contractVerifies {
+		if _, ok := verifyOf[strings.ToLower(contractVerify.Address)]; !ok {
+			verifyOf[strings.ToLower(contractVerify.Address)] = contractVerify
+		}
+	}
+	return verifyOf, nil
+}
+
 func getContractVerifyByQuery(ctx context.Context, query interface{}, args ...interface{}) (*busi.EVMContractVerify,
 	error) {
 	var contractVerify busi.EVMContractVerify
@@ -756,8 +777,7 @@ func ListCompileVersion(ctx context.Context) (interface{}, *utils.BuErrorRespons
 }
 
 func GetContractIsContract(ctx context.Context, address string) (interface{}, *utils.BuErrorResponse) {
-	count, err := utils.EngineGroup[utils.TaskDB].Where("address=? or filecoin_address=?", address, address).
-		Table(new(busi.EVMContract)).Count()
+	count, err := canonicalSession("evm_contract").Where("address=?", address).Count()
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -785,66 +805,58 @@ func GetContractIsVerify(ctx context.Context, address string) (interface{}, *uti
 	return result, nil
 }
 
-func ListTXNs(ctx context.Context, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+func ListTXNs(ctx context.Context, r *ListTxnsParams) (interface{}, *utils.BuErrorResponse) {
 	var (
-		c        busi.EVMContract
 		txnsList TxnsList
 	)
 
+	cond, resp := txnFilterCondOf("", &r.TxnFilter)
+	if resp != nil {
+		return nil, resp
+	}
+
 	// get the numbers of txns
-	total, err := busiTableRecordsCount(&c)
+	total, exact, err := txnHits("", cond, r)
 	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	txnsList.Hits = total
-	if txnsList.Hits <= 0 {
+	txnsList.Hits, txnsList.Exact = total, exact
+	if r.Cursor == "" && txnsList.Hits <= 0 {
 		return txnsList, nil
 	}
 
-	evmTransaction := make([]*busi.EVMTransaction, 0)
-	if err := busiSQLExecute(r, &evmTransaction); err != nil {
-		return nil, err
+	evmTransaction, next, prev, resp := evmTransactionFind(cond, &r.ListQuery, r.Order)
+	if resp != nil {
+		return nil, resp
 	}
-	for _, transaction := range evmTransaction {
-		if transaction.To == "" {
-			transaction.MethodName = "create"
-		} else {
-			transaction.MethodName, transaction.MethodSig, transaction.Params = parseMethodAndParamsFromContract(transaction.Input,
-				transaction.To)
-		}
+	txns, err := enrichTXNs(evmTransaction)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
-	txnsList.EVMTransaction = evmTransaction
+	txnsList.EVMTransaction, txnsList.Next, txnsList.Prev = txns, next, prev
 	return txnsList, nil
 }
 
 func GetTXN(ctx context.Context, hash string) (*EVMTransaction, *utils.BuErrorResponse) {
-	evmTransactions := make([]*busi.EVMTransaction, 0)
+	var evmTransaction busi.EVMTransaction
 
 	// get txn
-	err := utils.EngineGroup[utils.TaskDB].Where("hash = ?", hash).Find(&evmTransactions)
+	exist, err := canonicalSession("evm_transaction").Where("hash = ?", hash).Get(&evmTransaction)
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	if len(evmTransactions) == 0 {
+	if !exist {
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
 	}
 
-	var (
-		maxHeight      int64
-		evmTransaction busi.EVMTransaction
-	)
-	for _, t := range evmTransactions {
-		if t.Height > maxHeight {
-			maxHeight = t.Height
-			evmTransaction = *t
-		}
-	}
-
 	if evmTransaction.To == "" {
 		evmTransaction.MethodName = "create"
 	} else {
@@ -863,7 +875,7 @@ func GetTXN(ctx context.Context, hash string) (*EVMTransaction, *utils.BuErrorRe
 
 	evmContract := new(busi.EVMContract)
 	if evmTransaction.To != "" {
-		resp.ToIsContract, err = utils.EngineGroup[utils.TaskDB].Where("address = ?",
+		resp.ToIsContract, err = canonicalSession("evm_contract").Where("address = ?",
 			evmTransaction.To).Get(evmContract)
 		if err != nil {
 			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -871,24 +883,9 @@ func GetTXN(ctx context.Context, hash string) (*EVMTransaction, *utils.BuErrorRe
 		}
 	}
 
-	// confirmation blocks count
-	result, err := utils.EngineGroup[utils.TaskDB].QueryString("select max(height) from evm_block_header;")
-	if err != nil {
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
-	}
-	max_height, err := strconv.ParseInt(result[0]["max"], 10, 64)
-	if err != nil {
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
-	}
-	if max_height > 0 && (max_height > resp.Height) {
-		resp.ConfirmationBlocks = int64(math.Max(50, float64(max_height-resp.Height)))
-	}
-
 	// get txn status
 	evmReceipt := new(busi.EVMReceipt)
-	b, err := utils.EngineGroup[utils.TaskDB].Where("transaction_hash = ?", evmTransaction.Hash).Get(evmReceipt)
+	b, err := canonicalSession("evm_receipt").Where("transaction_hash = ?", evmTransaction.Hash).Get(evmReceipt)
 	if err != nil {
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
@@ -897,91 +894,161 @@ func GetTXN(ctx context.Context, hash string) (*EVMTransaction, *utils.BuErrorRe
 		resp.TxnStatus = int(evmReceipt.Status)
 	} else {
 		resp.TxnStatus = TxPending
+		evmReceipt = nil
+	}
+
+	// fees and timestamp
+	var evmBlockHeader *busi.EVMBlockHeader
+	if evmTransaction.BlockHash != "" {
+		evmBlockHeader = new(busi.EVMBlockHeader)
+		exist, err = canonicalSession("evm_block_header").Where("hash = ?", evmTransaction.BlockHash).
+			Get(evmBlockHeader)
+		if err != nil {
+			log.Errorf("Execute sql error: %v", err)
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
+		if !exist {
+			evmBlockHeader = nil
+		}
+	}
+	enrichTXN(&resp, evmReceipt, evmBlockHeader)
+
+	// confirmation blocks count
+	resp.ConfirmationBlocks, resp.Finality, err = confirmationsOf(resp.Height, b)
+	if err != nil {
+		log.Errorf("get chain head error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
 	return &resp, nil
 }
 
-func GetBlock(ctx context.Context, heightStr string) (interface{}, *utils.BuErrorResponse) {
+// GetAddress returns an address, or a contract, with its activity summary, label and token holdings.
+func GetAddress(ctx context.Context, address string) (interface{}, *utils.BuErrorResponse) {
+	evmAddress := new(busi.EVMAddress)
 
-	height, err := strconv.ParseInt(heightStr, 10, 64)
+	b, err := canonicalSession("evm_address").Where("address=?", address).Get(evmAddress)
 	if err != nil {
-		log.Errorf("GetBlock ParseInt err: %v", err)
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	evmBlockHeader := new(busi.EVMBlockHeader)
-
-	b, err := utils.EngineGroup[utils.TaskDB].Where("height = ?", height).Get(evmBlockHeader)
+	evmContract := new(busi.EVMContract)
+	isContract, err := canonicalSession("evm_contract").Where("address=?", address).Get(evmContract)
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
-
+	if !b && !isContract {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusNotFound,
+			Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
 	if !b {
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
+		evmAddress.Height, evmAddress.Address, evmAddress.FilecoinAddress, evmAddress.Balance =
+			evmContract.Height, evmContract.Address, evmContract.FilecoinAddress, evmContract.Balance
 	}
 
-	return evmBlockHeader, nil
-}
-
-func GetAddress(ctx context.Context, address string) (interface{}, *utils.BuErrorResponse) {
-	evmAddress := new(busi.EVMAddress)
+	resp := Address{
+		Height:          evmAddress.Height,
+		Address:         evmAddress.Address,
+		EthAddress:      ethcommon.HexToAddress(evmAddress.Address).Hex(),
+		FilecoinAddress: filecoinAddressOf(evmAddress.Address, evmAddress.FilecoinAddress),
+		Balance:         evmAddress.Balance,
+		Nonce:           evmAddress.Nonce,
+		IsContract:      isContract,
+	}
 
-	b, err := utils.EngineGroup[utils.TaskDB].
-		Where("address=? or filecoin_address=?", address, address).
-		OrderBy("height desc").Get(evmAddress)
+	summary, err := getAddressSummary(address)
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
-	if !b {
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusNotFound,
-			Response: utils.ErrBlockExplorerAPIServerNotFound}
+	// contracts created since the last summary
+	if isContract && summary.Creator == "" {
+		creatorTx, err := findCreatorTransaction(address)
+		if err != nil {
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
+		if creatorTx != nil {
+			summary.Creator, summary.CreationTxn = creatorTx.From, creatorTx.Hash
+		}
+	}
+	resp.AddressSummary = *summary
+
+	label := new(busi.EVMAddressLabel)
+	if _, err := utils.EngineGroup[utils.APIDB].Where("address = ?", address).OrderBy("id").Get(label); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	resp.Label, resp.LabelCategory = label.Label, label.Category
+
+	if resp.TokenHoldings, err = getTokenHoldings(address, summary.SummarizedHeight); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
-	return evmAddress, nil
+
+	return resp, nil
 }
 
-func ListAddressTXNs(ctx context.Context, address string, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+func ListAddressTXNs(ctx context.Context, address string, r *ListTxnsParams) (interface{}, *utils.BuErrorResponse) {
 	var (
 		txnsList TxnsList
 	)
 
+	cond, resp := txnFilterCondOf(address, &r.TxnFilter)
+	if resp != nil {
+		return nil, resp
+	}
+
 	// get the numbers of transactions
-	total, err := evmTransactionCount(address)
+	total, exact, err := txnHits(address, cond, r)
 	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	txnsList.Hits = total
-	if txnsList.Hits <= 0 {
+	txnsList.Hits, txnsList.Exact = total, exact
+	if r.Cursor == "" && txnsList.Hits <= 0 {
 		return txnsList, nil
 	}
 
 	// get transactions list
-	transactions := make([]*busi.EVMTransaction, 0)
-	if err := evmTransactionFind(address, r, &transactions); err != nil {
-		return nil, err
+	transactions, next, prev, resp := evmTransactionFind(cond, &r.ListQuery, r.Order)
+	if resp != nil {
+		return nil, resp
 	}
-	for _, transaction := range transactions {
-		if transaction.To == "" {
-			transaction.MethodName = "create"
-		} else {
-			transaction.MethodName, transaction.MethodSig, transaction.Params = parseMethodAndParamsFromContract(transaction.Input,
-				transaction.To)
-		}
+	txns, err := enrichTXNs(transactions)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
-	txnsList.EVMTransaction = transactions
+	txnsList.EVMTransaction, txnsList.Next, txnsList.Prev = txns, next, prev
 
 	return txnsList, nil
 }
 
 func GetSearchTextType(ctx context.Context, text string) (interface{}, *utils.BuErrorResponse) {
-	count, err := utils.EngineGroup[utils.TaskDB].Table(new(busi.EVMAddress)).
-		Where("address=? or filecoin_address=?", text, text).Count()
+	// addresses may be given in any of their forms
+	values := []interface{}{strings.ToLower(text)}
+	if address, err := utils.NormalizeAddress(text); err == nil {
+		values = values[:0]
+		for _, v := range address.QueryValues() {
+			values = append(values, v)
+		}
+	}
+
+	count, err := canonicalSession("evm_address").
+		Where(builder.Or(builder.In("address", values...), builder.In("filecoin_address", values...))).Count()
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -991,8 +1058,8 @@ func GetSearchTextType(ctx context.Context, text string) (interface{}, *utils.Bu
 		return SearchTextType{Type: "address"}, nil
 	}
 
-	count, err = utils.EngineGroup[utils.TaskDB].Table(new(busi.EVMContract)).
-		Where("address=? or filecoin_address=?", text, text).Count()
+	count, err = canonicalSession("evm_contract").
+		Where(builder.Or(builder.In("address", values...), builder.In("filecoin_address", values...))).Count()
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -1002,8 +1069,8 @@ func GetSearchTextType(ctx context.Context, text string) (interface{}, *utils.Bu
 		return SearchTextType{Type: "contract"}, nil
 	}
 
-	count, err = utils.EngineGroup[utils.TaskDB].Table(new(busi.EVMTransaction)).
-		Where("hash=?", text).Count()
+	count, err = canonicalSession("evm_transaction").
+		Where("hash=?", strings.ToLower(text)).Count()
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -1020,8 +1087,7 @@ func ListTxnEvents(ctx context.Context, hash string) (interface{}, *utils.BuErro
 	var (
 		receipt busi.EVMReceipt
 	)
-	exist, err := utils.EngineGroup[utils.TaskDB].Where("`transaction_hash`=?", hash).
-		OrderBy("height desc").Get(&receipt)
+	exist, err := canonicalSession("evm_receipt").Where("`transaction_hash`=?", hash).Get(&receipt)
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
@@ -1038,7 +1104,7 @@ func ListTxnEvents(ctx context.Context, hash string) (interface{}, *utils.BuErro
 		log.Errorf("Execute sql error: %v", err)
 		return nil, buErr
 	}
-	return EventList{Events: events, Hits: len(events)}, nil
+	return EventList{Events: events, Hits: int64(len(events)), Exact: true}, nil
 }
 
 func ListInternalTXNsByTxHash(ctx context.Context, hash string, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
@@ -1046,29 +1112,45 @@ func ListInternalTXNsByTxHash(ctx context.Context, hash string, r *ListQuery) (i
 		internalTXNsList InternalTxnsList
 	)
 
-	count, err := utils.EngineGroup[utils.TaskDB].Table(new(busi.EVMInternalTX)).
-		Where("parent_hash=?", hash).Count()
-	if err != nil {
-		log.Errorf("Execute sql error: %v", err)
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
-	}
+	if r.Cursor == "" || r.Total {
+		count, err := canonicalSession("evm_internal_tx").Where("parent_hash=?", hash).Count()
+		if err != nil {
+			log.Errorf("Execute sql error: %v", err)
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
 
-	internalTXNsList.Hits = count
-	if internalTXNsList.Hits <= 0 {
-		return internalTXNsList, nil
+		internalTXNsList.Hits, internalTXNsList.Exact = count, true
+		if internalTXNsList.Hits <= 0 {
+			return internalTXNsList, nil
+		}
 	}
 
+	return findInternalTxnsPage(canonicalSession("evm_internal_tx").Where("parent_hash=?", hash), r,
+		internalTXNsList)
+}
+
+// findInternalTxnsPage finds a page of the internal txns of sess, latest first, with their method names.
+func findInternalTxnsPage(sess *xorm.Session, r *ListQuery, internalTXNsList InternalTxnsList) (interface{},
+	*utils.BuErrorResponse) {
 	internalTxs := make([]*busi.EVMInternalTX, 0)
-	err = utils.EngineGroup[utils.TaskDB].Where("parent_hash=?", hash).
-		Limit(r.Limit, r.Offset).OrderBy("height desc").Find(&internalTxs)
+	next, prev, err := findPage(sess, internalTxnKeyset, r, OrderDesc, &internalTxs, func(i int) *listCursor {
+		return &listCursor{Height: internalTxs[i].Height, Key: internalTxs[i].Hash}
+	})
+	if err == errInvalidCursor {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+	}
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	internalTXNsList.EVMInternalTX = internalTxs
+	for _, internalTx := range internalTxs {
+		internalTx.MethodName = builtinCallName(internalTx.To)
+		internalTx.TypeName = callTypeName(internalTx.Type)
+	}
+	internalTXNsList.EVMInternalTX, internalTXNsList.Next, internalTXNsList.Prev = internalTxs, next, prev
 
 	return internalTXNsList, nil
 }
@@ -1128,105 +1210,154 @@ func ListContractBreakdown(ctx context.Context, r *ListStatContractBreakdownPara
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
+	// internal txns and FIL burned are accumulated per stat date in the background, rank the dates they're ready for
+	metricsLastDate, err := contractMetricsLastDate()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
 	lastDate := fvmContractSummaryDailyLastSateDate
+	if metricsLastDate < lastDate {
+		lastDate = metricsLastDate
+	}
+	statDate := lastDate
+	if r.Date != "" {
+		if r.Date > lastDate {
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+		}
+		statDate = r.Date
+	}
+
 	orderByMap := map[int]string{
 		ContractBreakDownOrderByTxnsAsc:  "txn_count asc",
 		ContractBreakDownOrderByTxnsDesc: "txn_count desc",
 
-		// TODO next iter
-		// ContractBreakDownOrderByInternalTxnsAsc: "",
-		// ContractBreakDownOrderByInternalTxnsDesc: "",
-		//
-		// ContractBreakDownOrderByFilBurnedAsc: "",
-		// ContractBreakDownOrderByFilBurneDesc: "",
-
 		ContractBreakDownOrderByUserCountAsc:  "user_count asc",
 		ContractBreakDownOrderByUserCountDesc: "user_count desc",
 
-		ContractBreakDownOrderByCallInAsc: fmt.Sprintf(`
-(
-    select call_count from fvm_contract_call_count_daily
-      where fvm_contract_call_count_daily.stat_date='%s'
-        and fvm_contract_call_count_daily.contract_address=fvm_contract_summary_daily.contract_address
-      and call_direction='in'
-) asc NULLS FIRST
-`, lastDate),
-		ContractBreakDownOrderByCallInDesc: fmt.Sprintf(`
-(
-    select call_count from fvm_contract_call_count_daily
-      where fvm_contract_call_count_daily.stat_date='%s'
-        and fvm_contract_call_count_daily.contract_address=fvm_contract_summary_daily.contract_address
-      and call_direction='in'
-) desc NULLS LAST
-`, lastDate),
-
-		ContractBreakDownOrderByCallOutAsc: fmt.Sprintf(`
-(
-    select call_count from fvm_contract_call_count_daily
-      where fvm_contract_call_count_daily.stat_date='%s'
-        and fvm_contract_call_count_daily.contract_address=fvm_contract_summary_daily.contract_address
-      and call_direction='out'
-) asc NULLS FIRST
-`, lastDate),
-		ContractBreakDownOrderByCallOutDesc: fmt.Sprintf(`
-(
-    select call_count from fvm_contract_call_count_daily
-      where fvm_contract_call_count_daily.stat_date='%s'
-        and fvm_contract_call_count_daily.contract_address=fvm_contract_summary_daily.contract_address
-      and call_direction='out'
-) desc NULLS LAST
-`, lastDate),
+		ContractBreakDownOrderByCallInAsc:  "call_in_count asc",
+		ContractBreakDownOrderByCallInDesc: "call_in_count desc",
+
+		ContractBreakDownOrderByCallOutAsc:  "call_out_count asc",
+		ContractBreakDownOrderByCallOutDesc: "call_out_count desc",
 	}
+	metricsOrderByMap := map[int]string{
+		ContractBreakDownOrderByInternalTxnsAsc:  "internal_txns asc",
+		ContractBreakDownOrderByInternalTxnsDesc: "internal_txns desc",
 
-	var (
-		fvmContractSummaryDailys []*busi.FVMContractSummaryDaily
+		ContractBreakDownOrderByFilBurnedAsc: "fil_burned asc",
+		ContractBreakDownOrderByFilBurneDesc: "fil_burned desc",
+	}
 
+	var (
 		contractBreakDownDetails []*StatContractBreakdownDetail
-		contractBreakdownResp    StatContractBreakdown
+		contractBreakdownResp    = StatContractBreakdown{StatDate: statDate, Window: r.Window}
 	)
 
 	contractBreakdownResp.Hits, err = utils.EngineGroup[utils.StatDB].
-		Table("fvm_contract_summary_daily").Where("stat_date=?", lastDate).Count()
+		Table("fvm_contract_summary_daily").Where("stat_date=?", statDate).Count()
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	db := utils.EngineGroup[utils.StatDB].Limit(r.Limit, r.Offset).Where("stat_date=?", lastDate)
-	if r.OrderBy > 0 {
-		err = db.OrderBy(orderByMap[r.OrderBy]).Find(&fvmContractSummaryDailys)
-	} else {
-		err = db.Find(&fvmContractSummaryDailys)
+	// a window ranks the difference between the snapshot of the stat date and the one of the window start
+	var startDate string
+	if days, ok := contractBreakdownWindowDays[r.Window]; ok {
+		date, _ := time.Parse(statDateLayout, statDate)
+		startDate = date.AddDate(0, 0, -days).Format(statDateLayout)
 	}
-
-	if err != nil {
-		log.Errorf("Execute sql error: %v", err)
-		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
-			Response: utils.ErrBlockExplorerAPIServerInternal}
+	statArgs, metricsArgs := []interface{}{statDate}, []interface{}{statDate}
+	if startDate != "" {
+		statArgs = []interface{}{statDate, startDate, startDate, statDate}
+		metricsArgs = []interface{}{startDate, statDate}
 	}
-	for index, fvmContractSummaryDaily := range fvmContractSummaryDailys {
-		contractBreakDownDetail := &StatContractBreakdownDetail{
-			Rank:            r.Offset + 1 + index,
-			ContractAddress: fvmContractSummaryDaily.ContractAddress,
-			TxnCount:        fvmContractSummaryDaily.TxnCount,
-			InternalTxns:    0,
-			FilBurned:       0,
-			UserCount:       fvmContractSummaryDaily.UserCount,
-		}
-		contractBreakDownDetail.CallInCount, err = getCallCount(lastDate,
-			fvmContractSummaryDaily.ContractAddress, "in")
-		if err != nil {
+
+	// the ranking of the page comes from the db of the order, the other columns of its contracts from the other one
+	var metrics []*contractMetricsRow
+	if metricsOrderBy, ok := metricsOrderByMap[r.OrderBy]; ok {
+		if err = utils.EngineGroup[utils.APIDB].SQL(contractMetricsSQL(startDate != "")+`
+order by `+metricsOrderBy+`, contract_address
+limit ? offset ?`, append(metricsArgs, r.Limit, r.Offset)...).Find(&metrics); err != nil {
+			log.Errorf("Execute sql error: %v", err)
 			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 				Response: utils.ErrBlockExplorerAPIServerInternal}
 		}
-		contractBreakDownDetail.CallOutCount, err = getCallCount(lastDate,
-			fvmContractSummaryDaily.ContractAddress, "out")
-		if err != nil {
+
+		details := make(map[string]*StatContractBreakdownDetail, len(metrics))
+		if len(metrics) > 0 {
+			addresses := make([]interface{}, 0, len(metrics))
+			for _, m := range metrics {
+				addresses = append(addresses, m.ContractAddress)
+			}
+			cond, condArgs, _ := builder.ToSQL(builder.In("contract_address", addresses...))
+
+			var stats []*StatContractBreakdownDetail
+			if err = utils.EngineGroup[utils.StatDB].
+				SQL("select * from ("+contractBreakdownSQL("", startDate != "", false)+") t where "+cond,
+					append(statArgs, condArgs...)...).Find(&stats); err != nil {
+				log.Errorf("Execute sql error: %v", err)
+				return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+					Response: utils.ErrBlockExplorerAPIServerInternal}
+			}
+			for _, stat := range stats {
+				details[stat.ContractAddress] = stat
+			}
+		}
+
+		for _, m := range metrics {
+			detail, ok := details[m.ContractAddress]
+			if !ok {
+				detail = &StatContractBreakdownDetail{ContractAddress: m.ContractAddress}
+			}
+			contractBreakDownDetails = append(contractBreakDownDetails, detail)
+		}
+	} else {
+		if err = utils.EngineGroup[utils.StatDB].
+			SQL(contractBreakdownSQL(orderByMap[r.OrderBy], startDate != "", true),
+				append(statArgs, r.Limit, r.Offset)...).
+			Find(&contractBreakDownDetails); err != nil {
+			log.Errorf("Execute sql error: %v", err)
 			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 				Response: utils.ErrBlockExplorerAPIServerInternal}
 		}
-		contractBreakDownDetails = append(contractBreakDownDetails, contractBreakDownDetail)
+
+		if len(contractBreakDownDetails) > 0 {
+			addresses := make([]interface{}, 0, len(contractBreakDownDetails))
+			for _, detail := range contractBreakDownDetails {
+				addresses = append(addresses, detail.ContractAddress)
+			}
+			cond, condArgs, _ := builder.ToSQL(builder.In("e.contract_address", addresses...))
+			if err = utils.EngineGroup[utils.APIDB].SQL(contractMetricsSQL(startDate != "")+" and "+cond,
+				append(metricsArgs, condArgs...)...).Find(&metrics); err != nil {
+				log.Errorf("Execute sql error: %v", err)
+				return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+					Response: utils.ErrBlockExplorerAPIServerInternal}
+			}
+		}
+	}
+
+	metricsOf := make(map[string]*contractMetricsRow, len(metrics))
+	for _, m := range metrics {
+		metricsOf[m.ContractAddress] = m
+	}
+	for _, contractBreakDownDetail := range contractBreakDownDetails {
+		if m, ok := metricsOf[contractBreakDownDetail.ContractAddress]; ok {
+			contractBreakDownDetail.InternalTxns = m.InternalTxns
+			contractBreakDownDetail.FilBurned, _ = new(big.Float).Quo(
+				new(big.Float).SetInt(parseAttoFIL(m.FilBurned)), attoFIL).Float64()
+		}
+	}
+
+	if err = fillContractNames(contractBreakDownDetails); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	for index, contractBreakDownDetail := range contractBreakDownDetails {
+		contractBreakDownDetail.Rank = r.Offset + 1 + index
 	}
 
 	contractBreakdownResp.Contracts = contractBreakDownDetails
@@ -1234,19 +1365,73 @@ func ListContractBreakdown(ctx context.Context, r *ListStatContractBreakdownPara
 	return contractBreakdownResp, nil
 }
 
-func getCallCount(statDate, contractAddress, direction string) (int64, error) {
-	var fvmContractCallCountDaily busi.FVMContractCallCountDaily
-	exist, err := utils.EngineGroup[utils.StatDB].
-		Where("stat_date=? and contract_address=? and call_direction=?", statDate, contractAddress, direction).
-		Get(&fvmContractCallCountDaily)
-	if err != nil {
-		log.Errorf("Execute sql error: %v", err)
-		return 0, err
+var contractBreakdownWindowDays = map[string]int{
+	ContractBreakDownWindow24h: 1,
+	ContractBreakDownWindow7d:  7,
+	ContractBreakDownWindow30d: 30,
+}
+
+const contractBreakdownSnapshotSQL = `
+select s.stat_date, s.contract_address, s.txn_count, s.user_count,
+       coalesce(ci.call_count, 0) as call_in_count, coalesce(co.call_count, 0) as call_out_count
+from fvm_contract_summary_daily s
+    left join fvm_contract_call_count_daily ci on ci.stat_date = s.stat_date
+        and ci.contract_address = s.contract_address and ci.call_direction = 'in'
+    left join fvm_contract_call_count_daily co on co.stat_date = s.stat_date
+        and co.contract_address = s.contract_address and co.call_direction = 'out'`
+
+// contractBreakdownSQL returns the contract summaries of a stat date joined with their call in/out counts, or their
+// increase since the start date of a window.
+func contractBreakdownSQL(orderBy string, window bool, paging bool) string {
+	sql := contractBreakdownSnapshotSQL + "\nwhere s.stat_date = ?"
+	if window {
+		sql = "with snapshot as (" + contractBreakdownSnapshotSQL + `
+where s.stat_date in (?, ?)
+)
+select e.contract_address,
+       e.txn_count - coalesce(b.txn_count, 0) as txn_count,
+       e.call_in_count - coalesce(b.call_in_count, 0) as call_in_count,
+       e.call_out_count - coalesce(b.call_out_count, 0) as call_out_count
+from snapshot e
+    left join snapshot b on b.contract_address = e.contract_address and b.stat_date = ?
+where e.stat_date = ?`
 	}
-	if exist {
-		return fvmContractCallCountDaily.CallCount, nil
+	if orderBy != "" {
+		sql += "\norder by " + orderBy + ", contract_address"
+	}
+	if paging {
+		sql += "\nlimit ? offset ?"
+	}
+	return sql
+}
+
+// fillContractNames sets the names of the verified contracts.
+func fillContractNames(details []*StatContractBreakdownDetail) error {
+	if len(details) == 0 {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(details))
+	for _, detail := range details {
+		addresses = append(addresses, detail.ContractAddress)
+	}
+
+	var contractVerifies []*busi.EVMContractVerify
+	if err := utils.EngineGroup[utils.APIDB].Cols("address", "contract_name").
+		Where("status=?", busi.EVMContractVerifyStatusSuccessfully).In("address", addresses).
+		Find(&contractVerifies); err != nil {
+		return err
+	}
+
+	names := make(map[string]string, len(contractVerifies))
+	for _, contractVerify := range contractVerifies {
+		names[contractVerify.Address] = contractVerify.ContractName
 	}
-	return 0, nil
+	for _, detail := range details {
+		detail.ContractName = names[detail.ContractAddress]
+	}
+
+	return nil
 }
 
 func getStatDBLastStatDate(tableName string) (string, error) {
@@ -0,0 +1,300 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"api-server/pkg/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"golang.org/x/crypto/blake2b"
+)
+
+// FEVM precompiles, see FIP-0054
+const (
+	PrecompileResolveAddress         = "0xfe00000000000000000000000000000000000001"
+	PrecompileLookupDelegatedAddress = "0xfe00000000000000000000000000000000000002"
+	PrecompileCallActor              = "0xfe00000000000000000000000000000000000003"
+	PrecompileGetActorType           = "0xfe00000000000000000000000000000000000004"
+	PrecompileCallActorByID          = "0xfe00000000000000000000000000000000000005"
+)
+
+// builtin actor method numbers, see https://github.com/filecoin-project/builtin-actors
+const (
+	MethodSend        uint64 = 0
+	MethodConstructor uint64 = 1
+)
+
+type builtinDecoder func(input []byte) (string, map[string]interface{}, error)
+
+type builtinPrecompile struct {
+	Name   string
+	Decode builtinDecoder
+}
+
+type builtinActor struct {
+	Name    string
+	Methods map[uint64]string
+}
+
+var (
+	builtinPrecompiles = map[string]*builtinPrecompile{
+		PrecompileResolveAddress:         {Name: "resolve_address", Decode: decodeResolveAddress},
+		PrecompileLookupDelegatedAddress: {Name: "lookup_delegated_address", Decode: decodeActorIDInput("lookup_delegated_address")},
+		PrecompileCallActor:              {Name: "call_actor", Decode: decodeCallActor},
+		PrecompileGetActorType:           {Name: "get_actor_type", Decode: decodeActorIDInput("get_actor_type")},
+		PrecompileCallActorByID:          {Name: "call_actor_id", Decode: decodeCallActorByID},
+
+		"0x0000000000000000000000000000000000000001": {Name: "ecrecover"},
+		"0x0000000000000000000000000000000000000002": {Name: "sha256"},
+		"0x0000000000000000000000000000000000000003": {Name: "ripemd160"},
+		"0x0000000000000000000000000000000000000004": {Name: "identity"},
+		"0x0000000000000000000000000000000000000005": {Name: "modexp"},
+		"0x0000000000000000000000000000000000000006": {Name: "ecadd"},
+		"0x0000000000000000000000000000000000000007": {Name: "ecmul"},
+		"0x0000000000000000000000000000000000000008": {Name: "ecpairing"},
+		"0x0000000000000000000000000000000000000009": {Name: "blake2f"},
+	}
+
+	// singleton builtin actors by actor ID
+	builtinActors = map[uint64]*builtinActor{
+		0: {Name: "system", Methods: map[uint64]string{1: "Constructor"}},
+		1: {Name: "init", Methods: map[uint64]string{1: "Constructor", 2: "Exec", 3: "Exec4"}},
+		2: {Name: "reward", Methods: map[uint64]string{1: "Constructor", 2: "AwardBlockReward", 3: "ThisEpochReward",
+			4: "UpdateNetworkKPI"}},
+		3: {Name: "cron", Methods: map[uint64]string{1: "Constructor", 2: "EpochTick"}},
+		4: {Name: "power", Methods: map[uint64]string{1: "Constructor", 2: "CreateMiner", 3: "UpdateClaimedPower",
+			4: "EnrollCronEvent", 5: "OnEpochTickEnd", 6: "UpdatePledgeTotal", 8: "SubmitPoRepForBulkVerify",
+			9: "CurrentTotalPower"}},
+		5: {Name: "market", Methods: map[uint64]string{1: "Constructor", 2: "AddBalance", 3: "WithdrawBalance",
+			4: "PublishStorageDeals", 5: "VerifyDealsForActivation", 6: "ActivateDeals", 7: "OnMinerSectorsTerminate",
+			8: "ComputeDataCommitment", 9: "CronTick"}},
+		6: {Name: "verifreg", Methods: map[uint64]string{1: "Constructor", 2: "AddVerifier", 3: "RemoveVerifier",
+			4: "AddVerifiedClient", 7: "RemoveVerifiedClientDataCap", 8: "RemoveExpiredAllocations",
+			9: "ClaimAllocations", 10: "GetClaims", 11: "ExtendClaimTerms", 12: "RemoveExpiredClaims"}},
+		7:  {Name: "datacap", Methods: map[uint64]string{1: "Constructor"}},
+		10: {Name: "eam", Methods: map[uint64]string{1: "Constructor", 2: "Create", 3: "Create2", 4: "CreateExternal"}},
+		99: {Name: "burnt_funds", Methods: map[uint64]string{}},
+	}
+
+	// FRC-42 exported methods, the method numbers are derived from the names so they can be resolved for any actor
+	frc42Methods = map[uint64]string{}
+
+	callActorArgs   abi.Arguments
+	callActorIDArgs abi.Arguments
+)
+
+func init() {
+	for _, name := range []string{
+		// common
+		"Receive", "InvokeEVM", "GetBytecode", "GetBytecodeHash", "GetStorageAt", "Resurrect",
+		"AuthenticateMessage",
+		// market
+		"AddBalance", "WithdrawBalance", "PublishStorageDeals", "GetBalance", "GetDealDataCommitment",
+		"GetDealClient", "GetDealProvider", "GetDealLabel", "GetDealTerm", "GetDealTotalPrice",
+		"GetDealClientCollateral", "GetDealProviderCollateral", "GetDealVerified", "GetDealActivation",
+		// power
+		"CreateMiner", "NetworkRawPower", "MinerRawPower", "MinerCount", "MinerConsensusCount",
+		// verifreg
+		"AddVerifiedClient", "RemoveExpiredAllocations", "GetClaims", "ExtendClaimTerms", "RemoveExpiredClaims",
+		// datacap, FRC-46
+		"Mint", "Destroy", "Name", "Symbol", "TotalSupply", "Balance", "Transfer", "TransferFrom",
+		"IncreaseAllowance", "DecreaseAllowance", "RevokeAllowance", "Burn", "BurnFrom", "Allowance",
+		"Granularity",
+		// miner
+		"ChangeWorkerAddress", "ChangePeerID", "ChangeMultiaddrs", "ConfirmChangeWorkerAddress", "RepayDebt",
+		"ChangeOwnerAddress", "ChangeBeneficiary", "GetBeneficiary", "GetOwner", "IsControllingAddress",
+		"GetSectorSize", "GetAvailableBalance", "GetVestingFunds", "GetPeerID", "GetMultiaddrs",
+		// multisig
+		"Propose", "Approve", "Cancel",
+		// eam
+		"CreateExternal",
+	} {
+		frc42Methods[frc42MethodNumber(name)] = name
+	}
+
+	uint64Type, _ := abi.NewType("uint64", "", nil)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	callActorArgs = abi.Arguments{
+		{Name: "method", Type: uint64Type},
+		{Name: "value", Type: uint256Type},
+		{Name: "flags", Type: uint64Type},
+		{Name: "codec", Type: uint64Type},
+		{Name: "params", Type: bytesType},
+		{Name: "target", Type: bytesType},
+	}
+	callActorIDArgs = abi.Arguments{
+		{Name: "method", Type: uint64Type},
+		{Name: "value", Type: uint256Type},
+		{Name: "flags", Type: uint64Type},
+		{Name: "codec", Type: uint64Type},
+		{Name: "params", Type: bytesType},
+		{Name: "actor_id", Type: uint64Type},
+	}
+}
+
+// frc42MethodNumber computes the FRC-42 method number of name.
+func frc42MethodNumber(name string) uint64 {
+	digest := blake2b.Sum512([]byte("1|" + name))
+	for i := 0; i+4 <= len(digest); i += 4 {
+		if n := binary.BigEndian.Uint32(digest[i : i+4]); n >= 1<<24 {
+			return uint64(n)
+		}
+	}
+	return 0
+}
+
+// builtinMethodName resolves a native actor method number, using the actor table of singleton actors.
+func builtinMethodName(actorID *uint64, method uint64) string {
+	var prefix string
+	if actorID != nil {
+		if actor, ok := builtinActors[*actorID]; ok {
+			prefix = actor.Name + "."
+			if name, ok := actor.Methods[method]; ok {
+				return prefix + name
+			}
+		}
+	}
+
+	switch method {
+	case MethodSend:
+		return prefix + "Send"
+	case MethodConstructor:
+		return prefix + "Constructor"
+	}
+	if name, ok := frc42Methods[method]; ok {
+		return prefix + name
+	}
+	return fmt.Sprintf("%s%d", prefix, method)
+}
+
+// builtinCallee returns the precompile or singleton actor address belongs to.
+func builtinCallee(address string) (*builtinPrecompile, *builtinActor, *uint64) {
+	address = strings.ToLower(address)
+	if precompile, ok := builtinPrecompiles[address]; ok {
+		return precompile, nil, nil
+	}
+
+	eth, err := utils.ParseEthAddress(address)
+	if err != nil {
+		return nil, nil, nil
+	}
+	id, ok := utils.MaskedIDFromEthAddress(eth)
+	if !ok {
+		return nil, nil, nil
+	}
+	return nil, builtinActors[id], &id
+}
+
+// builtinCallName the name shown for calls into a precompile or builtin actor, empty otherwise.
+func builtinCallName(address string) string {
+	precompile, actor, _ := builtinCallee(address)
+	if precompile != nil {
+		return precompile.Name
+	}
+	if actor != nil {
+		return actor.Name
+	}
+	return ""
+}
+
+// parseBuiltinMethodAndParams decodes calls into the FEVM precompiles and the builtin actors.
+func parseBuiltinMethodAndParams(inputData []byte, address string) (string, string, map[string]interface{}, bool) {
+	precompile, actor, actorID := builtinCallee(address)
+	if precompile != nil {
+		if precompile.Decode == nil {
+			return precompile.Name, "", nil, true
+		}
+		sig, params, err := precompile.Decode(inputData)
+		if err != nil {
+			return precompile.Name, "", nil, true
+		}
+		return precompile.Name, sig, params, true
+	}
+
+	if actor != nil {
+		// eth transactions to native actors are sent as a plain transfer or an InvokeEVM call
+		if len(inputData) == 0 {
+			return builtinMethodName(actorID, MethodSend), "", nil, true
+		}
+		return builtinMethodName(actorID, frc42MethodNumber("InvokeEVM")), "", nil, true
+	}
+
+	return "", "", nil, false
+}
+
+func decodeResolveAddress(input []byte) (string, map[string]interface{}, error) {
+	address, err := utils.FilecoinAddressFromBytes(input, utils.NetworkPrefix())
+	if err != nil {
+		return "", nil, err
+	}
+	return "resolve_address(bytes address)", map[string]interface{}{"address": address}, nil
+}
+
+func decodeActorIDInput(name string) builtinDecoder {
+	return func(input []byte) (string, map[string]interface{}, error) {
+		if len(input) != 32 {
+			return "", nil, fmt.Errorf("%s: invalid input length %d", name, len(input))
+		}
+		id := new(big.Int).SetBytes(input)
+		if !id.IsUint64() {
+			return "", nil, fmt.Errorf("%s: actor id overflows", name)
+		}
+		return fmt.Sprintf("%s(uint64 actor_id)", name), map[string]interface{}{
+			"actor_id": id.Uint64(),
+			"address":  fmt.Sprintf("%c0%d", utils.NetworkPrefix(), id.Uint64()),
+		}, nil
+	}
+}
+
+func decodeCallActor(input []byte) (string, map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	if err := callActorArgs.UnpackIntoMap(params, input); err != nil {
+		return "", nil, err
+	}
+
+	var actorID *uint64
+	if target, ok := params["target"].([]byte); ok {
+		if address, err := utils.FilecoinAddressFromBytes(target, utils.NetworkPrefix()); err == nil {
+			params["target"] = address
+			if normalized, err := utils.NormalizeAddress(address); err == nil {
+				actorID = normalized.ActorID
+			}
+		}
+	}
+	decodeCallActorParams(params, actorID)
+
+	return "call_actor(uint64 method,uint256 value,uint64 flags,uint64 codec,bytes params,bytes target)", params, nil
+}
+
+func decodeCallActorByID(input []byte) (string, map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	if err := callActorIDArgs.UnpackIntoMap(params, input); err != nil {
+		return "", nil, err
+	}
+
+	var actorID *uint64
+	if id, ok := params["actor_id"].(uint64); ok {
+		actorID = &id
+		params["target"] = fmt.Sprintf("%c0%d", utils.NetworkPrefix(), id)
+	}
+	decodeCallActorParams(params, actorID)
+
+	return "call_actor_id(uint64 method,uint256 value,uint64 flags,uint64 codec,bytes params,uint64 actor_id)",
+		params, nil
+}
+
+func decodeCallActorParams(params map[string]interface{}, actorID *uint64) {
+	if method, ok := params["method"].(uint64); ok {
+		params["method_name"] = builtinMethodName(actorID, method)
+	}
+	if raw, ok := params["params"].([]byte); ok {
+		params["params"] = fmt.Sprintf("0x%x", raw)
+	}
+	if value, ok := params["value"].(*big.Int); ok {
+		params["value"] = value.String()
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+	"xorm.io/xorm"
+)
+
+// canonicalKeys the columns identifying a row of every versioned task_db table. Re-ingestion writes the rows of a
+// height again with a higher version, reorgs write them at another height; the canonical row of a key is the one
+// with the highest (height, version).
+var canonicalKeys = map[string][]string{
+	"evm_block_header": {"height"},
+	"evm_transaction":  {"hash"},
+	"evm_receipt":      {"transaction_hash"},
+	"evm_internal_tx":  {"parent_hash", "hash"},
+	"evm_address":      {"address"},
+	"evm_contract":     {"address"},
+}
+
+// canonicalCond returns the condition keeping only the canonical rows of table.
+func canonicalCond(table string) string {
+	return canonicalCondBy(table, canonicalKeys[table]...)
+}
+
+// canonicalCondBy returns the condition keeping only the latest version of every keys of table, e.g. keyed by
+// (address, height) to read the state history of an address.
+func canonicalCondBy(table string, keys ...string) string {
+	conds := make([]string, 0, len(keys))
+	for _, key := range keys {
+		conds = append(conds, fmt.Sprintf("v.%s = %s.%s", key, table, key))
+	}
+
+	return fmt.Sprintf("not exists (select 1 from %s v where %s and (v.height, v.version) > (%s.height, %s.version))",
+		table, strings.Join(conds, " and "), table, table)
+}
+
+// canonicalSession returns a task_db session reading only the canonical rows of table.
+func canonicalSession(table string) *xorm.Session {
+	return utils.EngineGroup[utils.TaskDB].Table(table).Where(canonicalCond(table))
+}
+
+// ListRowVersions lists every ingested version of the rows of a key, superseded ones included, to debug the
+// ingestion pipeline. Tables keyed by several columns are filtered by the first one.
+func ListRowVersions(ctx context.Context, table, key string) (interface{}, *utils.BuErrorResponse) {
+	keys, ok := canonicalKeys[table]
+	if !ok {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+	}
+
+	rows, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(
+		"select %s.*, %s as canonical from %s where %s = ? order by height desc, version desc",
+		table, canonicalCond(table), table, keys[0]), key).QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return RowVersions{Table: table, Keys: keys, Rows: rows}, nil
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FinalityPending   = "pending"
+	FinalityConfirmed = "confirmed"
+	FinalityFinalized = "finalized"
+)
+
+type ChainHead struct {
+	Height    int64     `json:"height"`
+	Timestamp int64     `json:"timestamp"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type chainHeadTracker struct {
+	mu   sync.RWMutex
+	head *ChainHead
+}
+
+var (
+	chainHead = &chainHeadTracker{}
+)
+
+// StartChainHeadTracker keeps the task_db chain head in memory, refreshing it every interval.
+func StartChainHeadTracker(ctx context.Context, interval time.Duration) {
+	if _, err := chainHead.refresh(); err != nil {
+		log.Errorf("refresh chain head error: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := chainHead.refresh(); err != nil {
+					log.Errorf("refresh chain head error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (t *chainHeadTracker) refresh() (*ChainHead, error) {
+	result, err := utils.EngineGroup[utils.TaskDB].
+		QueryString("select height, timestamp from evm_block_header order by height desc limit 1;")
+	if err != nil {
+		return nil, err
+	}
+
+	head := &ChainHead{UpdatedAt: time.Now()}
+	if len(result) > 0 {
+		if head.Height, err = strconv.ParseInt(result[0]["height"], 10, 64); err != nil {
+			return nil, err
+		}
+		if head.Timestamp, err = strconv.ParseInt(result[0]["timestamp"], 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	t.head = head
+	t.mu.Unlock()
+
+	return head, nil
+}
+
+// get returns the tracked head, loading it on first use.
+func (t *chainHeadTracker) get() (*ChainHead, error) {
+	t.mu.RLock()
+	head := t.head
+	t.mu.RUnlock()
+
+	if head != nil {
+		return head, nil
+	}
+	return t.refresh()
+}
+
+// confirmationsOf returns the confirmations of a block/transaction at height and its finality state.
+func confirmationsOf(height int64, included bool) (int64, string, error) {
+	if !included {
+		return 0, FinalityPending, nil
+	}
+
+	head, err := chainHead.get()
+	if err != nil {
+		return 0, "", err
+	}
+
+	confirmations, finality := head.confirmations(height)
+	return confirmations, finality, nil
+}
+
+// confirmations returns the confirmations of an included height and its finality state.
+func (h *ChainHead) confirmations(height int64) (int64, string) {
+	var confirmations int64
+	if h.Height > height {
+		confirmations = h.Height - height
+	}
+	if confirmations >= utils.CNF.APIServer.FinalityDepth {
+		return confirmations, FinalityFinalized
+	}
+	return confirmations, FinalityConfirmed
+}
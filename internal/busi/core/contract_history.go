@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// contractChangesSQL the states of a contract changing its bytecode or balance, the first one included. Nonce only
+// changes are left out. A state is destroyed when its bytecode is empty after an earlier state had some.
+var contractChangesSQL = fmt.Sprintf(`
+with states as (
+    select height, balance, nonce, byte_code, md5(byte_code) as code_hash,
+           lag(md5(byte_code)) over (order by height) as prev_code_hash,
+           lag(balance) over (order by height) as prev_balance,
+           bool_or(byte_code not in ('', '0x')) over (order by height
+               rows between unbounded preceding and 1 preceding) as code_set_before
+    from evm_contract
+    where address = ? and %s)
+select height, balance, nonce,
+       code_hash is distinct from prev_code_hash as code_changed,
+       balance is distinct from prev_balance as balance_changed,
+       byte_code in ('', '0x') and coalesce(code_set_before, false) as destroyed,
+       (length(byte_code) - case when byte_code like '0x%%' then 2 else 0 end) / 2 as code_size,
+       case when code_hash is distinct from prev_code_hash then byte_code else '' end as byte_code
+from states
+where code_hash is distinct from prev_code_hash or balance is distinct from prev_balance`,
+	canonicalCondBy("evm_contract", "address", "height"))
+
+// GetContractHistory lists the bytecode and balance changes of a contract, the latest first, and whether and when it
+// was destroyed.
+func GetContractHistory(ctx context.Context, address string, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+	latest := new(busi.EVMContract)
+	b, err := canonicalSession("evm_contract").Where("address = ?", address).Get(latest)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if !b {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	history := ContractHistory{Address: address, Changes: make([]*ContractChange, 0)}
+	if history.ContractDestruction, err = contractDestruction(latest); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	result, err := utils.EngineGroup[utils.TaskDB].SQL("select count(*) as count from ("+contractChangesSQL+") t",
+		address).QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if history.Hits, err = strconv.ParseInt(result[0]["count"], 10, 64); err != nil {
+		log.Errorf("parse contract changes count error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if history.Hits <= 0 {
+		return history, nil
+	}
+
+	if err := utils.EngineGroup[utils.TaskDB].SQL(contractChangesSQL+`
+order by height desc
+limit ? offset ?`, address, r.Limit, r.Offset).Find(&history.Changes); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	heights := make([]int64, 0, len(history.Changes))
+	for _, change := range history.Changes {
+		heights = append(heights, change.Height)
+	}
+	timestamps, err := blockTimestamps(heights)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	for _, change := range history.Changes {
+		change.Timestamp = timestamps[change.Height]
+		change.BalanceFIL = formatFIL(parseAttoFIL(change.Balance))
+	}
+
+	return history, nil
+}
+
+// contractDestruction tells whether the contract of its latest state was destroyed, its bytecode emptied after it was
+// set, and when: at the first of the empty states since the bytecode was last set. A contract whose bytecode was
+// never set wasn't destroyed.
+func contractDestruction(latest *busi.EVMContract) (ContractDestruction, error) {
+	var destruction ContractDestruction
+	if !isEmptyCode(latest.ByteCode) {
+		return destruction, nil
+	}
+
+	canonical := canonicalCondBy("evm_contract", "address", "height")
+	result, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+with code_set as (
+    select max(height) as height from evm_contract
+    where address = ? and byte_code not in ('', '0x') and %s)
+select coalesce(min(height), 0) as height from evm_contract
+where address = ? and %s
+  and height > (select height from code_set)`, canonical, canonical),
+		latest.Address, latest.Address).QueryString()
+	if err != nil {
+		return destruction, err
+	}
+	height, err := strconv.ParseInt(result[0]["height"], 10, 64)
+	if err != nil {
+		return destruction, err
+	}
+	// no earlier state with bytecode
+	if height <= 0 {
+		return destruction, nil
+	}
+
+	timestamps, err := blockTimestamps([]int64{height})
+	if err != nil {
+		return destruction, err
+	}
+
+	destruction.Destroyed, destruction.DestroyedHeight, destruction.DestroyedTime = true, height, timestamps[height]
+	return destruction, nil
+}
+
+func isEmptyCode(code string) bool {
+	return code == "" || code == "0x"
+}
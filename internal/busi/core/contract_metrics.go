@@ -0,0 +1,370 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+	"xorm.io/xorm"
+)
+
+const (
+	contractMetricsInsertSize = 500
+	contractMetricsInterval   = 10 * time.Minute
+)
+
+// contractMetrics the task_db metrics of a contract, FIL burned in attoFIL.
+type contractMetrics struct {
+	InternalTxns int64
+	FilBurned    *big.Int
+}
+
+var attoFIL = new(big.Float).SetFloat64(1e18)
+
+// parseAttoFIL parses a decimal or 0x hex amount, empty or invalid amounts are 0.
+func parseAttoFIL(s string) *big.Int {
+	v, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		_, ok = v.SetString(s[2:], 16)
+	} else {
+		_, ok = v.SetString(s, 10)
+	}
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+// StartContractMetricsAggregator accumulates the contract metrics of the newly finalized stat dates every interval.
+func StartContractMetricsAggregator(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(contractMetricsInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := aggregateContractMetricsDaily(ctx); err != nil {
+				log.Errorf("aggregate contract metrics error: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// aggregateContractMetricsDaily accumulates the metrics of the contracts of fvm_contract_summary_daily into
+// evm_contract_metrics_daily, a stat date at a time once its heights are finalized, so that the breakdown ranks them
+// in SQL. Replicas take turns through an advisory lock.
+func aggregateContractMetricsDaily(ctx context.Context) error {
+	lastDate, err := getStatDBLastStatDate("fvm_contract_summary_daily")
+	if err != nil || lastDate == "" {
+		return err
+	}
+	head, err := chainHead.get()
+	if err != nil {
+		return err
+	}
+	finalized := head.Height - utils.CNF.APIServer.FinalityDepth
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		date, err := nextContractMetricsDate()
+		if err != nil || date == "" || date > lastDate {
+			return err
+		}
+		// the heights of the date end where the next date starts, it's complete once that one is finalized
+		endHeight, err := statDateEndHeight(date)
+		if err != nil {
+			return err
+		}
+		if endHeight >= finalized {
+			return nil
+		}
+
+		done, err := aggregateContractMetricsOf(date, endHeight)
+		if err != nil || !done {
+			return err
+		}
+	}
+}
+
+// nextContractMetricsDate returns the date following the last aggregated one, the first stat date before any.
+func nextContractMetricsDate() (string, error) {
+	last, err := contractMetricsLastDate()
+	if err != nil {
+		return "", err
+	}
+	if last != "" {
+		date, err := time.Parse(statDateLayout, last)
+		if err != nil {
+			return "", err
+		}
+		return date.AddDate(0, 0, 1).Format(statDateLayout), nil
+	}
+
+	result, err := utils.EngineGroup[utils.StatDB].QueryString(
+		"select coalesce(to_char(min(stat_date), 'YYYY-MM-DD'), '') as date from fvm_contract_summary_daily")
+	if err != nil {
+		return "", err
+	}
+	return result[0]["date"], nil
+}
+
+// aggregateContractMetricsOf adds the metrics of the heights of date, up to endHeight, to the ones accumulated up to
+// the previous date. The first date accumulates from genesis. It's false when another replica holds the lock.
+func aggregateContractMetricsOf(date string, endHeight int64) (bool, error) {
+	day, err := time.Parse(statDateLayout, date)
+	if err != nil {
+		return false, err
+	}
+	prevDate := day.AddDate(0, 0, -1).Format(statDateLayout)
+
+	session := utils.EngineGroup[utils.APIDB].NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return false, err
+	}
+	defer session.Rollback()
+
+	locked, err := tryJobLock(session, "contract_metrics_daily")
+	if err != nil || !locked {
+		return false, err
+	}
+	// another replica may have aggregated it meanwhile
+	next, err := session.QueryString(
+		"select coalesce(to_char(max(stat_date) + 1, 'YYYY-MM-DD'), '') as date from evm_contract_metrics_daily")
+	if err != nil {
+		return false, err
+	}
+	if next[0]["date"] != "" && next[0]["date"] != date {
+		return true, nil
+	}
+
+	metrics := make(map[string]*contractMetrics)
+	metricsOf := func(address string) *contractMetrics {
+		m, ok := metrics[address]
+		if !ok {
+			m = &contractMetrics{FilBurned: new(big.Int)}
+			metrics[address] = m
+		}
+		return m
+	}
+
+	// the contracts ranked on the date, zeros included, and the ones already accumulated
+	var addresses []string
+	if err := utils.EngineGroup[utils.StatDB].Table("fvm_contract_summary_daily").Cols("contract_address").
+		Where("stat_date = ?", date).Find(&addresses); err != nil {
+		return false, err
+	}
+	for _, address := range addresses {
+		metricsOf(address)
+	}
+
+	var fromHeight int64
+	if next[0]["date"] != "" {
+		prev, err := session.SQL(`select contract_address, internal_txns, fil_burned from evm_contract_metrics_daily
+where stat_date = ?`, prevDate).QueryString()
+		if err != nil {
+			return false, err
+		}
+		for _, row := range prev {
+			m := metricsOf(row["contract_address"])
+			if m.InternalTxns, err = strconv.ParseInt(row["internal_txns"], 10, 64); err != nil {
+				return false, err
+			}
+			m.FilBurned = parseAttoFIL(row["fil_burned"])
+		}
+		if fromHeight, err = statDateEndHeight(prevDate); err != nil {
+			return false, err
+		}
+	}
+
+	delta, err := aggregateContractMetrics(fromHeight, endHeight)
+	if err != nil {
+		return false, err
+	}
+	for address, d := range delta {
+		m := metricsOf(address)
+		m.InternalTxns += d.InternalTxns
+		m.FilBurned.Add(m.FilBurned, d.FilBurned)
+	}
+
+	if err := insertContractMetrics(session, date, metrics); err != nil {
+		return false, err
+	}
+
+	return true, session.Commit()
+}
+
+// tryJobLock takes the advisory lock of a background job for the transaction of session, false when another replica
+// holds it.
+func tryJobLock(session *xorm.Session, job string) (bool, error) {
+	result, err := session.QueryString("select pg_try_advisory_xact_lock(hashtext(?)) as locked", job)
+	if err != nil {
+		return false, err
+	}
+	return result[0]["locked"] == "true", nil
+}
+
+func insertContractMetrics(session *xorm.Session, date string, metrics map[string]*contractMetrics) error {
+	values := make([]string, 0, contractMetricsInsertSize)
+	args := make([]interface{}, 0, contractMetricsInsertSize*4)
+
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		query := "insert into evm_contract_metrics_daily (stat_date, contract_address, internal_txns, fil_burned) values " +
+			strings.Join(values, ", ")
+		if _, err := session.Exec(append([]interface{}{query}, args...)...); err != nil {
+			return err
+		}
+		values, args = values[:0], args[:0]
+		return nil
+	}
+
+	for address, m := range metrics {
+		values = append(values, "(?, ?, ?, ?)")
+		args = append(args, date, address, m.InternalTxns, m.FilBurned.String())
+		if len(values) >= contractMetricsInsertSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+type contractMetricsRow struct {
+	ContractAddress string
+	InternalTxns    int64
+	FilBurned       string
+}
+
+// contractMetricsLastDate returns the last stat date the contract metrics are accumulated up to, empty before any.
+func contractMetricsLastDate() (string, error) {
+	result, err := utils.EngineGroup[utils.APIDB].QueryString(
+		"select coalesce(to_char(max(stat_date), 'YYYY-MM-DD'), '') as date from evm_contract_metrics_daily")
+	if err != nil {
+		return "", err
+	}
+	return result[0]["date"], nil
+}
+
+// contractMetricsSQL returns the metrics of the contracts accumulated up to a stat date, or their increase since the
+// start date of a window.
+func contractMetricsSQL(window bool) string {
+	if window {
+		return `
+select e.contract_address,
+       e.internal_txns - coalesce(b.internal_txns, 0) as internal_txns,
+       e.fil_burned - coalesce(b.fil_burned, 0) as fil_burned
+from evm_contract_metrics_daily e
+    left join evm_contract_metrics_daily b on b.contract_address = e.contract_address and b.stat_date = ?
+where e.stat_date = ?`
+	}
+
+	return `
+select e.contract_address, e.internal_txns, e.fil_burned
+from evm_contract_metrics_daily e
+where e.stat_date = ?`
+}
+
+// aggregateContractMetrics returns the internal txns and FIL burned of every contract between fromHeight (excluded)
+// and toHeight.
+func aggregateContractMetrics(fromHeight, toHeight int64) (map[string]*contractMetrics, error) {
+	metrics := make(map[string]*contractMetrics)
+	metricsOf := func(address string) *contractMetrics {
+		m, ok := metrics[address]
+		if !ok {
+			m = &contractMetrics{FilBurned: new(big.Int)}
+			metrics[address] = m
+		}
+		return m
+	}
+
+	// internal txns sent or received by the contract, a self call counts once
+	internalTxns, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select address, count(*) as internal_txns from (
+    select "from" as address from evm_internal_tx
+      where height > ? and height <= ? and %s
+    union all
+    select "to" as address from evm_internal_tx
+      where height > ? and height <= ? and "to" <> "from" and %s
+) t
+where address in (select address from evm_contract)
+group by address`, canonicalCond("evm_internal_tx"), canonicalCond("evm_internal_tx")),
+		fromHeight, toHeight, fromHeight, toHeight).QueryString()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range internalTxns {
+		count, err := strconv.ParseInt(row["internal_txns"], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		metricsOf(row["address"]).InternalTxns = count
+	}
+
+	// the base fee of the gas used by the txns calling the contract is burnt
+	filBurned, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select evm_receipt."to" as address,
+       sum(evm_receipt.gas_used::numeric * evm_block_header.base_fee_per_gas::numeric) as fil_burned
+from evm_receipt
+    join evm_block_header on evm_block_header.hash = evm_receipt.block_hash and %s
+where evm_receipt.height > ? and evm_receipt.height <= ? and %s
+  and evm_receipt."to" in (select address from evm_contract)
+group by evm_receipt."to"`, canonicalCond("evm_block_header"), canonicalCond("evm_receipt")), fromHeight, toHeight).QueryString()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range filBurned {
+		burned, ok := new(big.Int).SetString(row["fil_burned"], 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid fil burned %s of %s", row["fil_burned"], row["address"])
+		}
+		metricsOf(row["address"]).FilBurned = burned
+	}
+
+	return metrics, nil
+}
+
+// statDateEndHeight returns the last height of a stat date (UTC).
+func statDateEndHeight(statDate string) (int64, error) {
+	date, err := time.Parse(statDateLayout, statDate)
+	if err != nil {
+		return 0, err
+	}
+
+	return heightBefore(date.AddDate(0, 0, 1))
+}
+
+// heightBefore returns the last height with a timestamp before t.
+func heightBefore(t time.Time) (int64, error) {
+	result, err := utils.EngineGroup[utils.TaskDB].
+		SQL("select coalesce(max(height), 0) as height from evm_block_header where timestamp < ?", t.Unix()).
+		QueryString()
+	if err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(result[0]["height"], 10, 64)
+}
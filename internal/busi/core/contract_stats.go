@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetContractStats returns the daily analytics of a contract between r.From and r.To: the txn/user/call counts of
+// its stat snapshots and the FIL it received and sent on the day.
+func GetContractStats(ctx context.Context, address string, r *StatRangeParams) (interface{}, *utils.BuErrorResponse) {
+	var summaries []*ContractStatsPoint
+	if err := utils.EngineGroup[utils.StatDB].SQL(`
+select to_char(s.stat_date, 'YYYY-MM-DD') as date, s.txn_count, s.user_count,
+       coalesce(ci.call_count, 0) as call_in_count, coalesce(co.call_count, 0) as call_out_count
+from fvm_contract_summary_daily s
+    left join fvm_contract_call_count_daily ci on ci.stat_date = s.stat_date
+        and ci.contract_address = s.contract_address and ci.call_direction = 'in'
+    left join fvm_contract_call_count_daily co on co.stat_date = s.stat_date
+        and co.contract_address = s.contract_address and co.call_direction = 'out'
+where s.contract_address = ? and s.stat_date >= ? and s.stat_date <= ?
+order by s.stat_date`, address, r.From, r.To).Find(&summaries); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	flows, err := contractValueFlows(address, r.From, r.To)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	// days without a stat snapshot still get their value flow
+	points := make(map[string]*ContractStatsPoint, len(summaries))
+	for _, summary := range summaries {
+		points[summary.Date] = summary
+	}
+	for date, flow := range flows {
+		point, ok := points[date]
+		if !ok {
+			point = &ContractStatsPoint{Date: date}
+			points[date] = point
+			summaries = append(summaries, point)
+		}
+		point.ValueIn, point.ValueOut = flow[0], flow[1]
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Date < summaries[j].Date
+	})
+
+	return ContractStats{Address: address, From: r.From, To: r.To, Points: summaries}, nil
+}
+
+// contractValueFlows returns the FIL received and sent by successful txns of address, by UTC day.
+func contractValueFlows(address, from, to string) (map[string][2]float64, error) {
+	fromDate, err := time.Parse(statDateLayout, from)
+	if err != nil {
+		return nil, err
+	}
+	toDate, err := time.Parse(statDateLayout, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select to_char(to_timestamp(evm_block_header.timestamp) at time zone 'UTC', 'YYYY-MM-DD') as date,
+       sum(case when evm_transaction."to" = ? then evm_transaction.value::numeric else 0 end) as value_in,
+       sum(case when evm_transaction."from" = ? then evm_transaction.value::numeric else 0 end) as value_out
+from evm_transaction
+    join evm_block_header on evm_block_header.hash = evm_transaction.block_hash and %s
+    join evm_receipt on evm_receipt.transaction_hash = evm_transaction.hash and %s
+where (evm_transaction."to" = ? or evm_transaction."from" = ?) and %s
+  and evm_receipt.status = 1
+  and evm_block_header.timestamp >= ? and evm_block_header.timestamp < ?
+group by 1`, canonicalCond("evm_block_header"), canonicalCond("evm_receipt"), canonicalCond("evm_transaction")),
+		address, address, address, address, fromDate.Unix(), toDate.AddDate(0, 0, 1).Unix()).QueryString()
+	if err != nil {
+		return nil, err
+	}
+
+	flows := make(map[string][2]float64, len(rows))
+	for _, row := range rows {
+		var flow [2]float64
+		for i, key := range []string{"value_in", "value_out"} {
+			value, ok := new(big.Float).SetString(row[key])
+			if !ok {
+				return nil, fmt.Errorf("invalid %s %s of %s", key, row[key], row["date"])
+			}
+			flow[i], _ = new(big.Float).Quo(value, attoFIL).Float64()
+		}
+		flows[row["date"]] = flow
+	}
+
+	return flows, nil
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"api-server/pkg/utils"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+var errInvalidCursor = errors.New("the cursor is invalid")
+
+// blockList the kind of the block list cursors, its pages are height ranges rather than keyset scans
+const blockList = "block"
+
+// listCursor the position of a row in a list ordered by (height, index), index being a number (txn index) or a
+// string (internal txn hash). Prev cursors page backwards from the row. The list kind and order it was issued for
+// are kept, a cursor is only valid for the same ones.
+type listCursor struct {
+	List   string `json:"l"`
+	Order  string `json:"o"`
+	Height int64  `json:"h"`
+	Index  int64  `json:"i,omitempty"`
+	Key    string `json:"k,omitempty"`
+	Prev   bool   `json:"p,omitempty"`
+}
+
+func (c *listCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*listCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var c listCursor
+	if err = json.Unmarshal(b, &c); err != nil {
+		return nil, errInvalidCursor
+	}
+	return &c, nil
+}
+
+// decodeListCursor decodes a cursor issued for the list kind in order.
+func decodeListCursor(s, list, order string) (*listCursor, error) {
+	c, err := decodeCursor(s)
+	if err != nil {
+		return nil, err
+	}
+	if c.List != list || c.Order != order {
+		return nil, errInvalidCursor
+	}
+	return c, nil
+}
+
+// keyset the columns a list is ordered by.
+type keyset struct {
+	// list the kind of the list, kept in its cursors
+	list   string
+	height string
+	index  string
+	// indexIsKey the index column is a string, e.g. a hash
+	indexIsKey bool
+}
+
+var (
+	txnKeyset         = keyset{list: "txn", height: "height", index: "transaction_index"}
+	internalTxnKeyset = keyset{list: "internal_txn", height: "evm_internal_tx.height", index: "evm_internal_tx.hash",
+		indexIsKey: true}
+)
+
+// findPage finds a page of sess rows into rowsSlicePtr, by offset or after/before the cursor of r, and returns the
+// cursors of the next and previous pages, empty at the ends. cursorOf returns the position of the i-th row. A cursor
+// of another list or order is errInvalidCursor.
+func findPage(sess *xorm.Session, k keyset, r *ListQuery, order string, rowsSlicePtr interface{},
+	cursorOf func(i int) *listCursor) (string, string, error) {
+	var c *listCursor
+	if r.Cursor != "" {
+		var err error
+		if c, err = decodeListCursor(r.Cursor, k.list, order); err != nil {
+			return "", "", err
+		}
+	}
+
+	// paging backwards scans in the reverse order
+	backward := c != nil && c.Prev
+	scanOrder := order
+	if backward {
+		scanOrder = reverseOrder(order)
+	}
+
+	// one more row tells whether there's another page
+	if c != nil {
+		op := ">"
+		if scanOrder == OrderDesc {
+			op = "<"
+		}
+		var index interface{} = c.Index
+		if k.indexIsKey {
+			index = c.Key
+		}
+		sess = sess.And(fmt.Sprintf("(%s, %s) %s (?, ?)", k.height, k.index, op), c.Height, index).Limit(r.Limit + 1)
+	} else {
+		sess = sess.Limit(r.Limit+1, r.Offset)
+	}
+	if err := sess.OrderBy(fmt.Sprintf("%s %s, %s %s", k.height, scanOrder, k.index, scanOrder)).
+		Find(rowsSlicePtr); err != nil {
+		return "", "", err
+	}
+
+	n, hasNext, hasPrev := trimPage(rowsSlicePtr, r.Limit, backward, c != nil || r.Offset > 0)
+	if n == 0 {
+		return "", "", nil
+	}
+
+	var next, prev string
+	if hasNext {
+		cursor := cursorOf(n - 1)
+		cursor.List, cursor.Order = k.list, order
+		next = cursor.encode()
+	}
+	if hasPrev {
+		cursor := cursorOf(0)
+		cursor.List, cursor.Order, cursor.Prev = k.list, order, true
+		prev = cursor.encode()
+	}
+
+	return next, prev, nil
+}
+
+// trimPage cuts the rows scanned for a page of limit rows, one more telling there's another page, back into the list
+// order if they were scanned backwards, and returns their number and whether there are next and previous pages.
+// skipped tells whether the scan started after some rows, at a cursor or an offset.
+func trimPage(rowsSlicePtr interface{}, limit int, backward, skipped bool) (int, bool, bool) {
+	rows := reflect.Indirect(reflect.ValueOf(rowsSlicePtr))
+	more := rows.Len() > limit
+	if more {
+		rows.Set(rows.Slice(0, limit))
+	}
+	if backward {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	// backwards the next page is the one the cursor came from, the previous one is the further rows
+	if backward {
+		return rows.Len(), true, more
+	}
+	return rows.Len(), more, skipped
+}
+
+func reverseOrder(order string) string {
+	if order == OrderAsc {
+		return OrderDesc
+	}
+	return OrderAsc
+}
+
+// estimateCount returns the planner estimate of the rows of sql on engine, cheap whatever their number.
+func estimateCount(engine *xorm.Engine, sql string, args ...interface{}) (int64, error) {
+	result, err := engine.SQL("explain (format json) "+sql, args...).QueryString()
+	if err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+
+	var plans []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	for _, v := range result[0] {
+		if err = json.Unmarshal([]byte(v), &plans); err != nil {
+			return 0, err
+		}
+	}
+	if len(plans) == 0 {
+		return 0, nil
+	}
+
+	return int64(plans[0].Plan.PlanRows), nil
+}
+
+// estimateTableCount returns the planner estimate of the canonical rows of table matching cond.
+func estimateTableCount(table string, cond builder.Cond) (int64, error) {
+	sql, args, err := builder.Select("1").From(table).
+		Where(builder.And(builder.Expr(canonicalCond(table)), cond)).ToSQL()
+	if err != nil {
+		return 0, err
+	}
+	return estimateCount(utils.EngineGroup[utils.TaskDB], sql, args...)
+}
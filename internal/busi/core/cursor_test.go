@@ -0,0 +1,96 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrimPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		scanned     []int
+		limit       int
+		backward    bool
+		skipped     bool
+		wantRows    []int
+		wantHasNext bool
+		wantHasPrev bool
+	}{
+		{name: "first page with more", scanned: []int{9, 8, 7}, limit: 2, wantRows: []int{9, 8}, wantHasNext: true},
+		{name: "only page", scanned: []int{9, 8}, limit: 2, wantRows: []int{9, 8}},
+		{name: "last page after a cursor", scanned: []int{7}, limit: 2, skipped: true, wantRows: []int{7},
+			wantHasPrev: true},
+		{name: "middle page after an offset", scanned: []int{7, 6, 5}, limit: 2, skipped: true,
+			wantRows: []int{7, 6}, wantHasNext: true, wantHasPrev: true},
+		{name: "backward with more", scanned: []int{5, 6, 7}, limit: 2, backward: true, skipped: true,
+			wantRows: []int{6, 5}, wantHasNext: true, wantHasPrev: true},
+		{name: "backward to the first page", scanned: []int{8, 9}, limit: 2, backward: true, skipped: true,
+			wantRows: []int{9, 8}, wantHasNext: true},
+		{name: "empty", scanned: []int{}, limit: 2, wantRows: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := append([]int{}, tt.scanned...)
+			n, hasNext, hasPrev := trimPage(&rows, tt.limit, tt.backward, tt.skipped)
+			if !reflect.DeepEqual(rows, tt.wantRows) || n != len(tt.wantRows) {
+				t.Errorf("rows = %v (%d), want %v", rows, n, tt.wantRows)
+			}
+			if hasNext != tt.wantHasNext || hasPrev != tt.wantHasPrev {
+				t.Errorf("hasNext, hasPrev = %v, %v, want %v, %v", hasNext, hasPrev, tt.wantHasNext, tt.wantHasPrev)
+			}
+		})
+	}
+}
+
+func TestReverseOrder(t *testing.T) {
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{order: OrderAsc, want: OrderDesc},
+		{order: OrderDesc, want: OrderAsc},
+	}
+
+	for _, tt := range tests {
+		if got := reverseOrder(tt.order); got != tt.want {
+			t.Errorf("reverseOrder(%s) = %s, want %s", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeListCursor(t *testing.T) {
+	cursor := (&listCursor{List: "txn", Order: OrderDesc, Height: 100, Index: 3, Prev: true}).encode()
+
+	tests := []struct {
+		name    string
+		cursor  string
+		list    string
+		order   string
+		wantErr bool
+	}{
+		{name: "same list and order", cursor: cursor, list: "txn", order: OrderDesc},
+		{name: "another list", cursor: cursor, list: "log", order: OrderDesc, wantErr: true},
+		{name: "another order", cursor: cursor, list: "txn", order: OrderAsc, wantErr: true},
+		{name: "not base64", cursor: "!", list: "txn", order: OrderDesc, wantErr: true},
+		{name: "not json", cursor: "bm90IGpzb24", list: "txn", order: OrderDesc, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeListCursor(tt.cursor, tt.list, tt.order)
+			if tt.wantErr {
+				if err != errInvalidCursor {
+					t.Errorf("err = %v, want %v", err, errInvalidCursor)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if c.Height != 100 || c.Index != 3 || !c.Prev {
+				t.Errorf("cursor = %+v", c)
+			}
+		})
+	}
+}
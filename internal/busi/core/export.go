@@ -0,0 +1,414 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+	"xorm.io/xorm"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+type exportTxn struct {
+	Hash        string `json:"hash"`
+	BlockNumber int64  `json:"block_number"`
+	Timestamp   int64  `json:"timestamp"`
+	Direction   string `xorm:"-" json:"direction"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	ValueFIL    string `xorm:"-" json:"value_fil"`
+	MethodID    string `xorm:"'method_id'" json:"method_id"`
+	Status      int64  `json:"status"`
+	GasUsed     int64  `json:"gas_used"`
+	TxnFee      string `json:"txn_fee"`
+	TxnFeeFIL   string `xorm:"-" json:"txn_fee_fil"`
+}
+
+type exportInternalTxn struct {
+	ParentHash string `json:"parent_hash"`
+	Hash       string `json:"hash"`
+	Height     int64  `json:"height"`
+	Timestamp  int64  `json:"timestamp"`
+	Type       uint64 `json:"-"`
+	TypeName   string `xorm:"-" json:"type"`
+	Direction  string `xorm:"-" json:"direction"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Value      string `json:"value"`
+	ValueFIL   string `xorm:"-" json:"value_fil"`
+}
+
+type exportTokenTransfer struct {
+	BlockNumber     int64  `json:"block_number"`
+	TransactionHash string `json:"transaction_hash"`
+	LogIndex        int64  `json:"log_index"`
+	Token           string `json:"token"`
+	TokenType       string `xorm:"-" json:"token_type"`
+	Direction       string `xorm:"-" json:"direction"`
+	From            string `xorm:"-" json:"from"`
+	To              string `xorm:"-" json:"to"`
+	Value           string `xorm:"-" json:"value"`
+	TokenID         string `xorm:"-" json:"token_id"`
+	Topic1          string `json:"-"`
+	Topic2          string `json:"-"`
+	Topic3          string `json:"-"`
+	Data            string `json:"-"`
+}
+
+type exportEvent struct {
+	BlockNumber     int64  `json:"block_number"`
+	TransactionHash string `json:"transaction_hash"`
+	LogIndex        int64  `json:"log_index"`
+	Event           string `xorm:"-" json:"event"`
+	Topic0          string `json:"topic0"`
+	Topic1          string `json:"topic1"`
+	Topic2          string `json:"topic2"`
+	Topic3          string `json:"topic3"`
+	Data            string `json:"data"`
+}
+
+// ExportAddressTXNs streams the txns from or to an address, oldest first.
+func ExportAddressTXNs(ctx context.Context, address string, r *ExportParams, w io.Writer) *utils.BuErrorResponse {
+	from, to, err := exportHeights(r)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	// one more row tells the export is truncated
+	limit := exportLimit(r)
+
+	row := new(exportTxn)
+	session := utils.EngineGroup[utils.TaskDB].Context(ctx).SQL(fmt.Sprintf(`
+select evm_transaction.hash, evm_transaction.block_number, coalesce(evm_block_header.timestamp, 0) as timestamp,
+       evm_transaction."from", evm_transaction."to", evm_transaction.value,
+       case when evm_transaction.input in ('', '0x') then ''
+            when evm_transaction.input like '0x%%' then lower(substring(evm_transaction.input from 1 for 10))
+            else '0x' || lower(substring(evm_transaction.input from 1 for 8)) end as method_id,
+       coalesce(evm_receipt.status, -1) as status, coalesce(evm_receipt.gas_used, 0) as gas_used,
+       coalesce(evm_receipt.gas_used::numeric * evm_receipt.effective_gas_price, 0)::text as txn_fee
+from evm_transaction
+    left join evm_receipt on evm_receipt.transaction_hash = evm_transaction.hash and %s
+    left join evm_block_header on evm_block_header.hash = evm_transaction.block_hash and %s
+where (evm_transaction."from" = ? or evm_transaction."to" = ?)
+  and evm_transaction.height >= ? and evm_transaction.height <= ? and %s
+order by evm_transaction.height, evm_transaction.transaction_index
+limit ?`, canonicalCond("evm_receipt"), canonicalCond("evm_block_header"), canonicalCond("evm_transaction")),
+		address, address, from, to, limit+1)
+
+	if err := exportRows(w, r.Format, session, row, limit, func() error {
+		row.Direction = exportDirection(address, row.From, row.To)
+		row.ValueFIL = formatFIL(parseAttoFIL(row.Value))
+		row.TxnFeeFIL = formatFIL(parseAttoFIL(row.TxnFee))
+		return nil
+	}); err != nil {
+		log.Errorf("export txns of %s error: %v", address, err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return nil
+}
+
+// ExportAddressInternalTXNs streams the internal txns from or to an address, oldest first.
+func ExportAddressInternalTXNs(ctx context.Context, address string, r *ExportParams,
+	w io.Writer) *utils.BuErrorResponse {
+	from, to, err := exportHeights(r)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	// one more row tells the export is truncated
+	limit := exportLimit(r)
+
+	row := new(exportInternalTxn)
+	session := utils.EngineGroup[utils.TaskDB].Context(ctx).SQL(fmt.Sprintf(`
+select evm_internal_tx.parent_hash, evm_internal_tx.hash, evm_internal_tx.height,
+       coalesce(evm_block_header.timestamp, 0) as timestamp, evm_internal_tx.type,
+       evm_internal_tx."from", evm_internal_tx."to", evm_internal_tx.value
+from evm_internal_tx
+    left join evm_block_header on evm_block_header.height = evm_internal_tx.height and %s
+where (evm_internal_tx."from" = ? or evm_internal_tx."to" = ?)
+  and evm_internal_tx.height >= ? and evm_internal_tx.height <= ? and %s
+order by evm_internal_tx.height, evm_internal_tx.parent_hash, evm_internal_tx.hash
+limit ?`, canonicalCond("evm_block_header"), canonicalCond("evm_internal_tx")),
+		address, address, from, to, limit+1)
+
+	if err := exportRows(w, r.Format, session, row, limit, func() error {
+		row.TypeName = callTypeName(row.Type)
+		row.Direction = exportDirection(address, row.From, row.To)
+		row.ValueFIL = formatFIL(parseAttoFIL(row.Value))
+		return nil
+	}); err != nil {
+		log.Errorf("export internal txns of %s error: %v", address, err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return nil
+}
+
+// ExportAddressTokenTransfers streams the erc20/erc721 Transfer logs from or to an address, oldest first.
+func ExportAddressTokenTransfers(ctx context.Context, address string, r *ExportParams,
+	w io.Writer) *utils.BuErrorResponse {
+	from, to, err := exportHeights(r)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	// one more row tells the export is truncated
+	limit := exportLimit(r)
+
+	topic := "0x000000000000000000000000" + strings.TrimPrefix(address, "0x")
+	row := new(exportTokenTransfer)
+	session := utils.EngineGroup[utils.APIDB].Context(ctx).SQL(`
+select block_number, transaction_hash, log_index, address as token, topic1, topic2, topic3, data
+from evm_log
+where topic0 = ? and (topic1 = ? or topic2 = ?) and height >= ? and height <= ?
+order by height, position
+limit ?`, transferTopic, topic, topic, from, to, limit+1)
+
+	if err := exportRows(w, r.Format, session, row, limit, func() error {
+		row.From, row.To, row.Value, row.TokenID = "", "", "", ""
+		if len(row.Topic1) == 66 && len(row.Topic2) == 66 {
+			row.From, row.To = "0x"+row.Topic1[26:], "0x"+row.Topic2[26:]
+		}
+		row.Direction = exportDirection(address, row.From, row.To)
+		if row.Topic3 == "" {
+			row.TokenType, row.Value = tokenTypeERC20, parseAttoFIL(row.Data).String()
+		} else {
+			row.TokenType, row.Value, row.TokenID = tokenTypeERC721, "1", parseAttoFIL(row.Topic3).String()
+		}
+		return nil
+	}); err != nil {
+		log.Errorf("export token transfers of %s error: %v", address, err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return nil
+}
+
+// ExportContractEvents streams the event logs emitted by a contract, oldest first, named with its ABI if verified.
+func ExportContractEvents(ctx context.Context, address string, r *ExportParams, w io.Writer) *utils.BuErrorResponse {
+	from, to, err := exportHeights(r)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	// one more row tells the export is truncated
+	limit := exportLimit(r)
+
+	contractABI, err := getContractABI(address)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	row := new(exportEvent)
+	session := utils.EngineGroup[utils.APIDB].Context(ctx).SQL(`
+select block_number, transaction_hash, log_index, topic0, topic1, topic2, topic3, data
+from evm_log
+where address = ? and height >= ? and height <= ?
+order by height, position
+limit ?`, address, from, to, limit+1)
+
+	if err := exportRows(w, r.Format, session, row, limit, func() error {
+		row.Event = ""
+		if contractABI != nil && row.Topic0 != "" {
+			if abiEvent, err := contractABI.EventByID(ethcommon.HexToHash(row.Topic0)); err == nil {
+				row.Event = abiEvent.String()
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Errorf("export events of %s error: %v", address, err)
+		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return nil
+}
+
+const (
+	exportTruncatedLimit = "limit"
+	exportTruncatedError = "error"
+)
+
+// exportTrailer closes an export missing some of the matching rows, because of the row limit or of an error while
+// streaming: the last NDJSON line, or the last CSV record "#truncated,<reason>,<rows>".
+type exportTrailer struct {
+	Truncated bool   `json:"truncated"`
+	Reason    string `json:"reason"`
+	Rows      int    `json:"rows"`
+}
+
+// exportRows streams the rows of a raw sql session, scanned one at a time into row and completed by fill, as CSV
+// with a header of the json names of row, or as NDJSON. The session selects up to limit+1 rows, the extra one only
+// telling the export is truncated.
+func exportRows(w io.Writer, format string, session *xorm.Session, row interface{}, limit int,
+	fill func() error) error {
+	rows, err := session.Rows(row)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns := exportColumns(reflect.TypeOf(row).Elem())
+
+	var (
+		encode  func() error
+		trailer func(*exportTrailer) error
+		flush   func() error
+	)
+	if format == ExportFormatNDJSON {
+		buffer := bufio.NewWriter(w)
+		encoder := json.NewEncoder(buffer)
+		encode = func() error { return encoder.Encode(row) }
+		trailer = func(t *exportTrailer) error { return encoder.Encode(t) }
+		flush = buffer.Flush
+	} else {
+		writer := csv.NewWriter(w)
+		header := make([]string, 0, len(columns))
+		for _, column := range columns {
+			header = append(header, column.name)
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		encode = func() error {
+			v := reflect.ValueOf(row).Elem()
+			for i, column := range columns {
+				record[i] = fmt.Sprint(v.Field(column.index).Interface())
+			}
+			return writer.Write(record)
+		}
+		trailer = func(t *exportTrailer) error {
+			return writer.Write([]string{"#truncated", t.Reason, strconv.Itoa(t.Rows)})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	written := 0
+	truncate := func(reason string) error {
+		if err := trailer(&exportTrailer{Truncated: true, Reason: reason, Rows: written}); err != nil {
+			return err
+		}
+		return flush()
+	}
+	// the rows streamed so far are kept, flagged as truncated
+	abort := func(err error) error {
+		truncate(exportTruncatedError)
+		return err
+	}
+
+	for rows.Next() {
+		if written >= limit {
+			return truncate(exportTruncatedLimit)
+		}
+		if err := rows.Scan(row); err != nil {
+			return abort(err)
+		}
+		if err := fill(); err != nil {
+			return abort(err)
+		}
+		if err := encode(); err != nil {
+			return abort(err)
+		}
+		written++
+	}
+	if err := rows.Err(); err != nil {
+		return abort(err)
+	}
+
+	return flush()
+}
+
+type exportColumn struct {
+	name  string
+	index int
+}
+
+// exportColumns returns the json named fields of an export row, in order.
+func exportColumns(t reflect.Type) []exportColumn {
+	columns := make([]exportColumn, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, exportColumn{name: name, index: i})
+	}
+	return columns
+}
+
+// exportHeights returns the heights of the block and date ranges of an export, both applied.
+func exportHeights(r *ExportParams) (int64, int64, error) {
+	from, to := r.FromBlock, r.ToBlock
+	if to == 0 {
+		to = math.MaxInt64
+	}
+
+	if r.From != "" {
+		date, _ := time.Parse(statDateLayout, r.From)
+		height, err := heightBefore(date)
+		if err != nil {
+			return 0, 0, err
+		}
+		if height+1 > from {
+			from = height + 1
+		}
+	}
+	if r.To != "" {
+		height, err := statDateEndHeight(r.To)
+		if err != nil {
+			return 0, 0, err
+		}
+		if height < to {
+			to = height
+		}
+	}
+
+	return from, to, nil
+}
+
+func exportLimit(r *ExportParams) int {
+	if r.Limit == 0 || r.Limit > utils.CNF.APIServer.ExportMaxRows {
+		return utils.CNF.APIServer.ExportMaxRows
+	}
+	return r.Limit
+}
+
+func exportDirection(address, from, to string) string {
+	switch {
+	case from == address && to == address:
+		return TxnDirectionSelf
+	case from == address:
+		return TxnDirectionOut
+	case to == address:
+		return TxnDirectionIn
+	}
+	return ""
+}
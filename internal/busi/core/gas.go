@@ -0,0 +1,198 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// gasTrackerBlocks recent blocks the suggested priority fees and average utilization are computed over
+	gasTrackerBlocks = 20
+
+	// maxGasHistoryBlocks one day of epochs, longer ranges are asked by date
+	maxGasHistoryBlocks = 2880
+	// maxGasHistoryDays one year of daily points
+	maxGasHistoryDays = 366
+)
+
+var gasConsumerWindows = map[string]time.Duration{
+	GasConsumersWindow24h: 24 * time.Hour,
+	GasConsumersWindow7d:  7 * 24 * time.Hour,
+	GasConsumersWindow30d: 30 * 24 * time.Hour,
+}
+
+// GetGasTracker returns the base fee and utilization of the latest block, and the priority fees (effective gas
+// price above the base fee) paid at the 25th/50th/75th percentiles by the txns of the recent blocks.
+func GetGasTracker(ctx context.Context) (interface{}, *utils.BuErrorResponse) {
+	var head busi.EVMBlockHeader
+	exist, err := canonicalSession("evm_block_header").Desc("height").Get(&head)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if !exist {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	tracker := GasTracker{
+		Height:        head.Height,
+		Timestamp:     head.Timestamp,
+		BaseFeePerGas: head.BaseFeePerGas,
+		GasUsed:       head.GasUsed,
+		GasLimit:      head.GasLimit,
+		Utilization:   utilization(head.GasUsed, head.GasLimit),
+		Blocks:        gasTrackerBlocks,
+	}
+	fromHeight := head.Height - gasTrackerBlocks
+
+	fees, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select coalesce(percentile_disc(0.25) within group (order by tip), 0) as low,
+       coalesce(percentile_disc(0.5) within group (order by tip), 0) as medium,
+       coalesce(percentile_disc(0.75) within group (order by tip), 0) as high
+from (
+    select greatest(evm_receipt.effective_gas_price - evm_block_header.base_fee_per_gas::numeric, 0) as tip
+    from evm_receipt
+        join evm_block_header on evm_block_header.hash = evm_receipt.block_hash and %s
+    where evm_receipt.height > ? and %s
+) t`, canonicalCond("evm_block_header"), canonicalCond("evm_receipt")), fromHeight).QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	tracker.PriorityFee = GasPriorityFee{Low: fees[0]["low"], Medium: fees[0]["medium"], High: fees[0]["high"]}
+
+	result, err := canonicalSession("evm_block_header").
+		Select("coalesce(avg(gas_used::numeric / nullif(gas_limit, 0)), 0) as utilization").
+		Where("height > ?", fromHeight).QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if tracker.AvgUtilization, err = strconv.ParseFloat(result[0]["utilization"], 64); err != nil {
+		log.Errorf("parse utilization error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return tracker, nil
+}
+
+// GetGasHistory returns the base fee and utilization of every block of a height range, or their daily averages
+// over a date range.
+func GetGasHistory(ctx context.Context, r *GasHistoryParams) (interface{}, *utils.BuErrorResponse) {
+	history := GasHistory{Points: make([]*GasHistoryPoint, 0)}
+
+	if r.ToHeight > 0 {
+		if r.ToHeight < r.FromHeight || r.ToHeight-r.FromHeight >= maxGasHistoryBlocks {
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+		}
+
+		var headers []*busi.EVMBlockHeader
+		if err := canonicalSession("evm_block_header").Cols("height", "timestamp", "base_fee_per_gas",
+			"gas_used", "gas_limit").Where("height >= ? and height <= ?", r.FromHeight, r.ToHeight).
+			Asc("height").Find(&headers); err != nil {
+			log.Errorf("Execute sql error: %v", err)
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
+		for _, header := range headers {
+			history.Points = append(history.Points, &GasHistoryPoint{
+				Height:        header.Height,
+				Timestamp:     header.Timestamp,
+				BaseFeePerGas: header.BaseFeePerGas,
+				GasUsed:       header.GasUsed,
+				GasLimit:      header.GasLimit,
+				Utilization:   utilization(header.GasUsed, header.GasLimit),
+			})
+		}
+
+		return history, nil
+	}
+
+	from, _ := time.Parse(statDateLayout, r.From)
+	to, _ := time.Parse(statDateLayout, r.To)
+	if to.Sub(from) >= maxGasHistoryDays*24*time.Hour {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+	}
+
+	if err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select to_char(to_timestamp(timestamp) at time zone 'UTC', 'YYYY-MM-DD') as date,
+       round(avg(base_fee_per_gas::numeric))::text as base_fee_per_gas,
+       sum(gas_used) as gas_used, sum(gas_limit) as gas_limit,
+       coalesce(avg(gas_used::numeric / nullif(gas_limit, 0)), 0) as utilization
+from evm_block_header
+where timestamp >= ? and timestamp < ? and %s
+group by 1
+order by 1`, canonicalCond("evm_block_header")), from.Unix(), to.AddDate(0, 0, 1).Unix()).
+		Find(&history.Points); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return history, nil
+}
+
+// ListTopGasConsumers ranks contracts by the gas used by the txns calling them in a recent window.
+func ListTopGasConsumers(ctx context.Context, r *ListTopGasConsumersParams) (interface{}, *utils.BuErrorResponse) {
+	fromHeight, err := heightBefore(time.Now().Add(-gasConsumerWindows[r.Window]))
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	consumersSQL := fmt.Sprintf(`
+select "to" as address, count(*) as txn_count, sum(gas_used) as gas_used,
+       sum(gas_used::numeric * effective_gas_price)::text as gas_fee
+from evm_receipt
+where height > ? and "to" in (select address from evm_contract) and %s
+group by "to"`, canonicalCond("evm_receipt"))
+
+	result, err := utils.EngineGroup[utils.TaskDB].SQL("select count(*) as count from ("+consumersSQL+") t",
+		fromHeight).QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	hits, err := strconv.ParseInt(result[0]["count"], 10, 64)
+	if err != nil {
+		log.Errorf("parse gas consumers count error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	var consumers []*GasConsumer
+	if err = utils.EngineGroup[utils.TaskDB].SQL(consumersSQL+`
+order by gas_used desc, address
+limit ? offset ?`, fromHeight, r.Limit, r.Offset).Find(&consumers); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	for index, consumer := range consumers {
+		consumer.Rank = r.Offset + 1 + index
+		consumer.FilecoinAddress = filecoinAddressOf(consumer.Address, "")
+	}
+
+	return GasConsumersList{Consumers: consumers, Hits: hits, Window: r.Window}, nil
+}
+
+func utilization(gasUsed, gasLimit int64) float64 {
+	if gasLimit == 0 {
+		return 0
+	}
+	return float64(gasUsed) / float64(gasLimit)
+}
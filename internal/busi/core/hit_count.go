@@ -0,0 +1,263 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"api-server/pkg/utils"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+const (
+	// tables estimated below this number of rows are counted exactly, it's cheap
+	exactCountMaxRows = 100000
+
+	txnCountCacheSize = 10000
+)
+
+// tableHits returns the number of canonical rows of table: the planner's reltuples estimate of large tables,
+// exactly counted for small ones, and whether it's exact. The estimate counts every version of the rows, the ones
+// superseded by reorgs included, so it's above the canonical count.
+func tableHits(table string) (int64, bool, error) {
+	estimate, err := reltuplesOf(utils.EngineGroup[utils.TaskDB], table)
+	if err != nil {
+		return 0, false, err
+	}
+	if estimate >= exactCountMaxRows {
+		return estimate, false, nil
+	}
+
+	count, err := canonicalSession(table).Count()
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+// reltuplesOf returns the planner's estimate of the rows of table, negative when it was never analyzed.
+func reltuplesOf(engine *xorm.Engine, table string) (int64, error) {
+	result, err := engine.SQL("select reltuples::bigint as estimate from pg_class where oid = to_regclass(?)",
+		table).QueryString()
+	if err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return -1, nil
+	}
+
+	return strconv.ParseInt(result[0]["estimate"], 10, 64)
+}
+
+// finalizedCount the number of rows of a key counted up to a finalized height, which can't change any more.
+type finalizedCount struct {
+	height int64
+	count  int64
+}
+
+// countCache caches the row counts of keys, e.g. the txns of addresses, adding the rows finalized since on each use.
+type countCache struct {
+	mu     sync.Mutex
+	counts map[string]*finalizedCount
+	// countAbove counts by key the rows of keys above a height, up to upTo unless it's negative
+	countAbove func(keys []interface{}, above, upTo int64) (map[string]int64, error)
+	// maxFinalized caps the finalized height if set, e.g. at the height a background job filled the rows up to
+	maxFinalized func() (int64, error)
+}
+
+var (
+	txnCounts = &countCache{counts: make(map[string]*finalizedCount),
+		countAbove: func(addresses []interface{}, above, upTo int64) (map[string]int64, error) {
+			canonical := builder.Expr(canonicalCond("evm_transaction"))
+			heights := heightRangeCond("height", above, upTo)
+			return addressCounts(utils.EngineGroup[utils.TaskDB],
+				builder.Select(`"from" as address`).From("evm_transaction").
+					Where(builder.And(builder.In(`"from"`, addresses...), heights, canonical)),
+				builder.Select(`"to" as address`).From("evm_transaction").
+					Where(builder.And(builder.In(`"to"`, addresses...), builder.Expr(`"to" != "from"`), heights,
+						canonical)))
+		}}
+
+	internalTxnCounts = &countCache{counts: make(map[string]*finalizedCount),
+		countAbove: func(addresses []interface{}, above, upTo int64) (map[string]int64, error) {
+			canonical := builder.And(builder.Expr(canonicalCond("evm_internal_tx")),
+				builder.Expr(canonicalCond("evm_transaction")))
+			heights := heightRangeCond("evm_internal_tx.height", above, upTo)
+			return addressCounts(utils.EngineGroup[utils.TaskDB],
+				builder.Select(`evm_transaction."from" as address`).From("evm_internal_tx").
+					InnerJoin("evm_transaction", "evm_internal_tx.parent_hash = evm_transaction.hash").
+					Where(builder.And(builder.In(`evm_transaction."from"`, addresses...), heights, canonical)),
+				builder.Select(`evm_transaction."to" as address`).From("evm_internal_tx").
+					InnerJoin("evm_transaction", "evm_internal_tx.parent_hash = evm_transaction.hash").
+					Where(builder.And(builder.In(`evm_transaction."to"`, addresses...),
+						builder.Expr(`evm_transaction."to" != evm_transaction."from"`), heights, canonical)))
+		}}
+
+	// the logs of the finalized heights are indexed once and for all, the indexer commits a batch of heights at once
+	logCounts = &countCache{counts: make(map[string]*finalizedCount),
+		countAbove: func(addresses []interface{}, above, upTo int64) (map[string]int64, error) {
+			return addressCounts(utils.EngineGroup[utils.APIDB], builder.Select("address").From("evm_log").
+				Where(builder.And(builder.In("address", addresses...), heightRangeCond("height", above, upTo))))
+		},
+		maxFinalized: func() (int64, error) {
+			result, err := utils.EngineGroup[utils.APIDB].
+				QueryString("select coalesce(max(height), 0) as height from evm_log")
+			if err != nil {
+				return 0, err
+			}
+			return strconv.ParseInt(result[0]["height"], 10, 64)
+		}}
+)
+
+// heightRangeCond returns the condition on a height column above a height, up to upTo unless it's negative.
+func heightRangeCond(column string, above, upTo int64) builder.Cond {
+	cond := builder.Cond(builder.Gt{column: above})
+	if upTo >= 0 {
+		cond = builder.And(cond, builder.Lte{column: upTo})
+	}
+	return cond
+}
+
+// addressCounts counts by address the rows of the union of selects, each selecting an address column.
+func addressCounts(engine *xorm.Engine, selects ...*builder.Builder) (map[string]int64, error) {
+	parts := make([]string, 0, len(selects))
+	args := make([]interface{}, 0)
+	for _, sel := range selects {
+		sql, selArgs, err := sel.ToSQL()
+		if err != nil {
+			return nil, err
+		}
+		parts, args = append(parts, sql), append(args, selArgs...)
+	}
+
+	var rows []struct {
+		Address string
+		Count   int64
+	}
+	if err := engine.SQL("select address, count(*) as count from ("+strings.Join(parts, " union all ")+
+		") t group by address", args...).Find(&rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Address] = row.Count
+	}
+	return counts, nil
+}
+
+// count returns the exact number of the rows of key, see countAll.
+func (c *countCache) count(key string) (int64, error) {
+	counts, err := c.countAll([]string{key})
+	if err != nil {
+		return 0, err
+	}
+	return counts[key], nil
+}
+
+// countAll returns the exact number of the rows of every key: the finalized ones are counted incrementally, the ones
+// above the finalized height every time, in one query for the keys counted from the same height.
+func (c *countCache) countAll(keys []string) (map[string]int64, error) {
+	head, err := chainHead.get()
+	if err != nil {
+		return nil, err
+	}
+	finalized := head.Height - utils.CNF.APIServer.FinalityDepth
+	if c.maxFinalized != nil {
+		upTo, err := c.maxFinalized()
+		if err != nil {
+			return nil, err
+		}
+		if upTo < finalized {
+			finalized = upTo
+		}
+	}
+	if finalized < 0 {
+		finalized = 0
+	}
+
+	counted := make(map[string]*finalizedCount, len(keys))
+	// the keys to count up to the finalized height, by the height of their cached count
+	stale := make(map[int64][]interface{})
+	c.mu.Lock()
+	for _, key := range keys {
+		if counted[key] != nil {
+			continue
+		}
+		cached, ok := c.counts[key]
+		if !ok {
+			cached = &finalizedCount{height: -1}
+		}
+		counted[key] = cached
+		if cached.height < finalized {
+			stale[cached.height] = append(stale[cached.height], key)
+		}
+	}
+	c.mu.Unlock()
+
+	for height, staleKeys := range stale {
+		counts, err := c.countAbove(staleKeys, height, finalized)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		for _, key := range staleKeys {
+			k := key.(string)
+			counted[k] = &finalizedCount{height: finalized, count: counted[k].count + counts[k]}
+			if len(c.counts) >= txnCountCacheSize {
+				for evicted := range c.counts {
+					delete(c.counts, evicted)
+					break
+				}
+			}
+			c.counts[k] = counted[k]
+		}
+		c.mu.Unlock()
+	}
+
+	// a key may have been cached above the finalized height by a concurrent use
+	recentKeys := make(map[int64][]interface{})
+	for key, count := range counted {
+		recentKeys[count.height] = append(recentKeys[count.height], key)
+	}
+	counts := make(map[string]int64, len(keys))
+	for height, heightKeys := range recentKeys {
+		recent, err := c.countAbove(heightKeys, height, -1)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range heightKeys {
+			counts[key.(string)] = counted[key.(string)].count + recent[key.(string)]
+		}
+	}
+
+	return counts, nil
+}
+
+// txnHits returns the number of the txns of a list and whether it's exact. Unfiltered lists of all txns use the
+// table estimate, of an address its cached count; with a cursor it's only returned when asked, as an estimate.
+func txnHits(address string, cond builder.Cond, r *ListTxnsParams) (int64, bool, error) {
+	if r.Cursor != "" {
+		if !r.Total {
+			return 0, false, nil
+		}
+		if address == "" && r.IsEmpty() {
+			return tableHits("evm_transaction")
+		}
+		count, err := estimateTableCount("evm_transaction", cond)
+		return count, false, err
+	}
+
+	if !r.IsEmpty() {
+		count, err := evmTransactionCount(cond)
+		return count, true, err
+	}
+	if address == "" {
+		return tableHits("evm_transaction")
+	}
+	count, err := txnCounts.count(address)
+	return count, true, err
+}
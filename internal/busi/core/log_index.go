@@ -0,0 +1,278 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+	"xorm.io/builder"
+)
+
+const (
+	// heights indexed in one transaction while catching up
+	logIndexBatchHeights = 2880
+	logIndexInsertSize   = 500
+)
+
+// logIndexer fills evm_log from the logs of the canonical receipts. Receipts above the finalized height may still be
+// superseded, so every pass checks them again and indexes the heights whose receipts changed. Replicas take turns
+// through an advisory lock.
+type logIndexer struct {
+	// indexed the height up to which the logs are indexed, -1 before the first pass
+	indexed int64
+}
+
+// StartIndexer indexes the logs of new receipts into evm_log every interval, then summarizes the addresses of the
+// newly finalized heights.
+func StartIndexer(ctx context.Context, interval time.Duration) {
+	indexer := &logIndexer{indexed: -1}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := indexer.index(ctx); err != nil {
+				log.Errorf("index logs error: %v", err)
+			} else if err := summarizeAddresses(ctx, indexer.indexed); err != nil {
+				log.Errorf("summarize addresses error: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (x *logIndexer) index(ctx context.Context) error {
+	if x.indexed < 0 {
+		result, err := utils.EngineGroup[utils.APIDB].
+			QueryString("select coalesce(max(height), 0) as height from evm_log;")
+		if err != nil {
+			return err
+		}
+		if x.indexed, err = strconv.ParseInt(result[0]["height"], 10, 64); err != nil {
+			return err
+		}
+	}
+
+	head, err := chainHead.get()
+	if err != nil {
+		return err
+	}
+	// nothing ingested yet
+	if head.Height <= 0 {
+		return nil
+	}
+
+	from := x.indexed
+	if finalized := head.Height - utils.CNF.APIServer.FinalityDepth; finalized < from {
+		from = finalized
+	}
+	if from < 0 {
+		from = 0
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		to := from + logIndexBatchHeights
+		if to >= head.Height {
+			to = head.Height
+		}
+		done, err := indexLogs(from, to, to == head.Height)
+		if err != nil || !done {
+			return err
+		}
+		x.indexed = to
+
+		if to == head.Height {
+			return nil
+		}
+		from = to
+	}
+}
+
+// indexLogs replaces the indexed logs of the heights in (from, to], and of the ones above if last, whose receipts
+// changed since they were indexed. It's false when another replica holds the lock.
+func indexLogs(from, to int64, last bool) (bool, error) {
+	session := utils.EngineGroup[utils.APIDB].NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return false, err
+	}
+	defer session.Rollback()
+
+	locked, err := tryJobLock(session, "log_index")
+	if err != nil || !locked {
+		return false, err
+	}
+
+	heightCond := builder.And(builder.Gt{"height": from}, builder.Lte{"height": to})
+	if last {
+		heightCond = builder.Gt{"height": from}
+	}
+
+	var indexed []*busi.EVMLogHeight
+	if err := session.Where(heightCond).Find(&indexed); err != nil {
+		return false, err
+	}
+	current, err := receiptsHashes(heightCond)
+	if err != nil {
+		return false, err
+	}
+	changed := changedHeights(indexed, current)
+
+	logs := make([]*busi.EVMLog, 0)
+	if len(changed) > 0 {
+		var receipts []*busi.EVMReceipt
+		if err := canonicalSession("evm_receipt").
+			Cols("height", "transaction_hash", "transaction_index", "block_hash", "block_number", "logs").
+			Where("logs != '[]'").In("height", changed...).
+			OrderBy("height, transaction_index").Find(&receipts); err != nil {
+			return false, err
+		}
+
+		var height, position int64
+		for _, receipt := range receipts {
+			if receipt.Height != height {
+				height, position = receipt.Height, 0
+			}
+
+			var ethLogs []types.Log
+			if err := json.Unmarshal([]byte(receipt.Logs), &ethLogs); err != nil {
+				log.Errorf("unmarshal logs of receipt %s error: %v", receipt.TransactionHash, err)
+				continue
+			}
+			for _, ethLog := range ethLogs {
+				logs = append(logs, newEVMLog(receipt, position, &ethLog))
+				position++
+			}
+		}
+
+		if _, err := session.In("height", changed...).Delete(new(busi.EVMLog)); err != nil {
+			return false, err
+		}
+		if _, err := session.In("height", changed...).Delete(new(busi.EVMLogHeight)); err != nil {
+			return false, err
+		}
+	}
+
+	for i := 0; i < len(logs); i += logIndexInsertSize {
+		j := i + logIndexInsertSize
+		if j > len(logs) {
+			j = len(logs)
+		}
+		if _, err := session.Insert(logs[i:j]); err != nil {
+			return false, err
+		}
+	}
+
+	hashes := make([]*busi.EVMLogHeight, 0, len(changed))
+	for _, height := range changed {
+		if hash, ok := current[height.(int64)]; ok {
+			hashes = append(hashes, &busi.EVMLogHeight{Height: height.(int64), ReceiptsHash: hash})
+		}
+	}
+	for i := 0; i < len(hashes); i += logIndexInsertSize {
+		j := i + logIndexInsertSize
+		if j > len(hashes) {
+			j = len(hashes)
+		}
+		if _, err := session.Insert(hashes[i:j]); err != nil {
+			return false, err
+		}
+	}
+
+	// the heights up to from are finalized, they're never checked again
+	if _, err := session.Where("height <= ?", from).Delete(new(busi.EVMLogHeight)); err != nil {
+		return false, err
+	}
+
+	return true, session.Commit()
+}
+
+// receiptsHashes returns the fingerprint of the canonical receipts with logs of every height matching heightCond.
+// A receipt superseded by another version, or moved by a reorg, changes the fingerprint of its height.
+func receiptsHashes(heightCond builder.Cond) (map[int64]string, error) {
+	cond, args, err := builder.ToSQL(heightCond)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*busi.EVMLogHeight
+	if err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select height, md5(string_agg(transaction_hash || ':' || version, ',' order by transaction_index)) as receipts_hash
+from evm_receipt
+where %s and logs != '[]' and %s
+group by height`, cond, canonicalCond("evm_receipt")), args...).Find(&rows); err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		hashes[row.Height] = row.ReceiptsHash
+	}
+	return hashes, nil
+}
+
+// changedHeights returns the heights whose receipts fingerprint differs from the indexed one, the ones having lost
+// their receipts included.
+func changedHeights(indexed []*busi.EVMLogHeight, current map[int64]string) []interface{} {
+	indexedHashes := make(map[int64]string, len(indexed))
+	for _, height := range indexed {
+		indexedHashes[height.Height] = height.ReceiptsHash
+	}
+
+	changed := make([]interface{}, 0)
+	for height, hash := range current {
+		if indexedHashes[height] != hash {
+			changed = append(changed, height)
+		}
+	}
+	for height := range indexedHashes {
+		if _, ok := current[height]; !ok {
+			changed = append(changed, height)
+		}
+	}
+	return changed
+}
+
+func newEVMLog(receipt *busi.EVMReceipt, position int64, ethLog *types.Log) *busi.EVMLog {
+	evmLog := &busi.EVMLog{
+		Height:           receipt.Height,
+		Position:         position,
+		LogIndex:         int64(ethLog.Index),
+		TransactionHash:  receipt.TransactionHash,
+		TransactionIndex: receipt.TransactionIndex,
+		BlockHash:        receipt.BlockHash,
+		BlockNumber:      receipt.BlockNumber,
+		Address:          strings.ToLower(ethLog.Address.Hex()),
+		Data:             hexutil.Encode(ethLog.Data),
+	}
+
+	topics := []*string{&evmLog.Topic0, &evmLog.Topic1, &evmLog.Topic2, &evmLog.Topic3}
+	for i, topic := range ethLog.Topics {
+		if i < len(topics) {
+			*topics[i] = topic.Hex()
+		}
+	}
+
+	return evmLog
+}
@@ -0,0 +1,292 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	log "github.com/sirupsen/logrus"
+	"xorm.io/builder"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	errInvalidLogFilter   = errors.New("invalid log filter")
+	errEventNeedsContract = fmt.Errorf("%w: the event name needs a verified contract, use a topic or a signature",
+		errInvalidLogFilter)
+
+	logKeyset = keyset{list: "log", height: "height", index: "position"}
+)
+
+// ListLogs lists the indexed event logs matching the filter, emitted by address if any, decoded with the ABI of
+// verified contracts.
+func ListLogs(ctx context.Context, address string, r *ListLogsParams) (interface{}, *utils.BuErrorResponse) {
+	var eventList EventList
+
+	cond, err := logFilterCond(address, &r.LogFilter)
+	if errors.Is(err, errInvalidLogFilter) {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK,
+			Response: utils.NewResponse(utils.CodeBadRequest, err.Error(), nil)}
+	}
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	eventList.Hits, eventList.Exact, err = logHits(address, cond, r)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if r.Cursor == "" && eventList.Hits <= 0 {
+		eventList.Events = make([]*Event, 0)
+		return eventList, nil
+	}
+
+	logs := make([]*busi.EVMLog, 0)
+	next, prev, err := findPage(utils.EngineGroup[utils.APIDB].Table("evm_log").Where(cond), logKeyset,
+		&r.ListQuery, r.Order, &logs, func(i int) *listCursor {
+			return &listCursor{Height: logs[i].Height, Index: logs[i].Position}
+		})
+	if err == errInvalidCursor {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+	}
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	events, err := eventsOf(logs)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	eventList.Events, eventList.Next, eventList.Prev = events, next, prev
+
+	return eventList, nil
+}
+
+// logFilterCond returns the condition on evm_log of a filter, address being the emitter if any; a bare event name
+// is resolved through its ABI.
+func logFilterCond(address string, f *LogFilter) (builder.Cond, error) {
+	cond := builder.NewCond()
+
+	if address != "" {
+		cond = cond.And(builder.Eq{"address": strings.ToLower(address)})
+	}
+
+	if f.Event != "" {
+		topics, err := eventTopics(address, f.Event)
+		if err != nil {
+			return nil, err
+		}
+		cond = cond.And(builder.In("topic0", topics...))
+	}
+
+	for i, topic := range []string{f.Topic0, f.Topic1, f.Topic2, f.Topic3} {
+		if topic != "" {
+			cond = cond.And(builder.Eq{fmt.Sprintf("topic%d", i): strings.ToLower(topic)})
+		}
+	}
+
+	if f.FromBlock > 0 {
+		cond = cond.And(builder.Gte{"height": f.FromBlock})
+	}
+	if f.ToBlock > 0 {
+		cond = cond.And(builder.Lte{"height": f.ToBlock})
+	}
+
+	return cond, nil
+}
+
+// eventTopics returns the topic0s of a 0x topic, a signature, or an event name of the verified contract at address.
+func eventTopics(address, event string) ([]interface{}, error) {
+	if topicRegexp.MatchString(event) {
+		return []interface{}{strings.ToLower(event)}, nil
+	}
+	if strings.Contains(event, "(") {
+		return []interface{}{hexutil.Encode(signatureHash(event))}, nil
+	}
+
+	if address == "" {
+		return nil, errEventNeedsContract
+	}
+	contractABI, err := getContractABI(address)
+	if err != nil {
+		return nil, err
+	}
+	if contractABI == nil {
+		return nil, errEventNeedsContract
+	}
+
+	topics := make([]interface{}, 0)
+	for _, abiEvent := range contractABI.Events {
+		if strings.EqualFold(abiEvent.RawName, event) {
+			topics = append(topics, strings.ToLower(abiEvent.ID.Hex()))
+		}
+	}
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("%w: event %s not found in the contract abi", errInvalidLogFilter, event)
+	}
+
+	return topics, nil
+}
+
+// logHits returns the number of logs matching cond and whether it's exact, like txnHits: the table estimate when
+// unfiltered, the cached count of the logs of a contract, the planner estimate of the other filters matching many
+// logs; with a cursor only when asked.
+func logHits(address string, cond builder.Cond, r *ListLogsParams) (int64, bool, error) {
+	if r.Cursor != "" && !r.Total {
+		return 0, false, nil
+	}
+
+	if address != "" && r.IsEmpty() {
+		count, err := logCounts.count(address)
+		return count, true, err
+	}
+
+	var estimate int64
+	var err error
+	if cond.IsValid() {
+		sql, args, err := builder.Select("1").From("evm_log").Where(cond).ToSQL()
+		if err != nil {
+			return 0, false, err
+		}
+		estimate, err = estimateCount(utils.EngineGroup[utils.APIDB], sql, args...)
+	} else {
+		estimate, err = reltuplesOf(utils.EngineGroup[utils.APIDB], "evm_log")
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if estimate >= exactCountMaxRows {
+		return estimate, false, nil
+	}
+
+	count, err := utils.EngineGroup[utils.APIDB].Table("evm_log").Where(cond).Count()
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+// eventsOf decodes a page of logs, loading the ABIs of their emitters and the methods of their txns in batches.
+func eventsOf(logs []*busi.EVMLog) ([]*Event, error) {
+	events := make([]*Event, 0, len(logs))
+	if len(logs) == 0 {
+		return events, nil
+	}
+
+	hashes := make([]string, 0, len(logs))
+	addresses := make([]string, 0, len(logs))
+	for _, l := range logs {
+		hashes = append(hashes, l.TransactionHash)
+		addresses = append(addresses, l.Address)
+	}
+
+	var transactions []*busi.EVMTransaction
+	if err := canonicalSession("evm_transaction").Cols("hash", "to", "input").In("hash", hashes).
+		Find(&transactions); err != nil {
+		return nil, err
+	}
+	txnOf := make(map[string]*busi.EVMTransaction, len(transactions))
+	for _, transaction := range transactions {
+		txnOf[transaction.Hash] = transaction
+		if transaction.To != "" {
+			addresses = append(addresses, transaction.To)
+		}
+	}
+
+	abis, err := getContractABIs(addresses)
+	if err != nil {
+		return nil, err
+	}
+	abiOf := func(address string) (*abi.ABI, error) {
+		return abis[strings.ToLower(address)], nil
+	}
+
+	for _, l := range logs {
+		event := &Event{
+			Address:     l.Address,
+			RawTopics:   make([]string, 0, 4),
+			RawData:     strings.TrimPrefix(l.Data, "0x"),
+			BlockNumber: uint64(l.BlockNumber),
+			TxHash:      l.TransactionHash,
+			TxIndex:     uint(l.TransactionIndex),
+			BlockHash:   l.BlockHash,
+			Index:       uint(l.LogIndex),
+		}
+
+		topics := make([]ethcommon.Hash, 0, 4)
+		for _, topic := range []string{l.Topic0, l.Topic1, l.Topic2, l.Topic3} {
+			if topic == "" {
+				break
+			}
+			event.RawTopics = append(event.RawTopics, topic)
+			topics = append(topics, ethcommon.HexToHash(topic))
+		}
+
+		data, err := hex.DecodeString(event.RawData)
+		if err == nil {
+			err = decodeEvent(event, abis[l.Address], topics, data)
+		}
+		if err != nil {
+			log.Errorf("decode log %d of %s error: %v", l.LogIndex, l.TransactionHash, err)
+		}
+
+		if transaction := txnOf[l.TransactionHash]; transaction != nil {
+			if transaction.To == "" {
+				event.MethodName = "create"
+			} else {
+				event.MethodName, _, _ = parseMethodAndParams(transaction.Input, transaction.To, abiOf)
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// decodeEvent fills the name, topics and data of event from the ABI of its emitter, events the ABI doesn't know are
+// left undecoded.
+func decodeEvent(event *Event, contractABI *abi.ABI, topics []ethcommon.Hash, data []byte) error {
+	if contractABI == nil || len(topics) == 0 {
+		return nil
+	}
+	abiEvent, err := contractABI.EventByID(topics[0])
+	if err != nil {
+		return nil
+	}
+
+	var indexedArgs []abi.Argument
+	for _, input := range abiEvent.Inputs {
+		if input.Indexed {
+			indexedArgs = append(indexedArgs, input)
+		}
+	}
+	parsedTopics := make(map[string]interface{})
+	if err = abi.ParseTopicsIntoMap(parsedTopics, indexedArgs, topics[1:]); err != nil {
+		return err
+	}
+	parsedData := make(map[string]interface{})
+	if err = abiEvent.Inputs.UnpackIntoMap(parsedData, data); err != nil {
+		return err
+	}
+
+	event.EventName, event.ParsedTopics, event.ParsedData = abiEvent.String(), parsedTopics, parsedData
+	return nil
+}
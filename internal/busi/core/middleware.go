@@ -16,6 +16,7 @@ import (
 	"github.com/imxyb/solc-go"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
+	"xorm.io/builder"
 )
 
 type contractsArr []*busi.EVMContract
@@ -40,24 +41,14 @@ func (x contractsArr) Swap(i, j int) {
 	x[i], x[j] = x[j], x[i]
 }
 
-func busiTableRecordsCount(prt interface{}) (int64, error) {
-	total, err := utils.EngineGroup[utils.TaskDB].Count(prt)
-	if err != nil {
-		log.Errorf("ListContracts execute sql error: %v", err)
-		return 0, err
-	}
-
-	return total, nil
-}
-
-func busiSQLExecute(r *ListQuery, rowsSlicePtr interface{}) *utils.BuErrorResponse {
+func busiSQLExecute(table string, r *ListQuery, rowsSlicePtr interface{}) *utils.BuErrorResponse {
 	v := reflect.ValueOf(rowsSlicePtr)
 	if v.Kind() != reflect.Ptr || reflect.Indirect(v).Kind() != reflect.Slice {
 		log.Errorf("needs a pointer to a slice, v.Kind() = %v, reflect.Indirect(v).Kind() = %v", v.Kind(), reflect.Indirect(v).Kind())
 		return nil
 	}
 
-	if err := utils.EngineGroup[utils.TaskDB].Limit(r.Limit, r.Offset).Desc("height").Find(rowsSlicePtr); err != nil {
+	if err := canonicalSession(table).Limit(r.Limit, r.Offset).Desc("height").Find(rowsSlicePtr); err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError, Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
@@ -66,14 +57,14 @@ func busiSQLExecute(r *ListQuery, rowsSlicePtr interface{}) *utils.BuErrorRespon
 
 func findCreatorTransaction(address string) (*busi.EVMTransaction, error) {
 	var receipt busi.EVMReceipt
-	exist, err := utils.EngineGroup[utils.TaskDB].Where("`to`='' and contract_address=?", address).Get(&receipt)
+	exist, err := canonicalSession("evm_receipt").Where("`to`='' and contract_address=?", address).Get(&receipt)
 	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
 		return nil, err
 	}
 	var tx busi.EVMTransaction
 	if exist {
-		exist, err = utils.EngineGroup[utils.TaskDB].Where("hash=?", receipt.TransactionHash).Get(&tx)
+		exist, err = canonicalSession("evm_transaction").Where("hash=?", receipt.TransactionHash).Get(&tx)
 		if err != nil {
 			log.Errorf("Execute sql error: %v", err)
 			return nil, err
@@ -85,25 +76,28 @@ func findCreatorTransaction(address string) (*busi.EVMTransaction, error) {
 	return nil, nil
 }
 
-func evmTransactionFind(address string, r *ListQuery, rowsSlicePtr interface{}) *utils.BuErrorResponse {
-	v := reflect.ValueOf(rowsSlicePtr)
-	if v.Kind() != reflect.Ptr || reflect.Indirect(v).Kind() != reflect.Slice {
-		log.Errorf("needs a pointer to a slice, v.Kind() = %v, reflect.Indirect(v).Kind() = %v", v.Kind(),
-			reflect.Indirect(v).Kind())
-		return nil
+// evmTransactionFind finds a page of the txns matching cond, returning the next and previous page cursors.
+func evmTransactionFind(cond builder.Cond, r *ListQuery, order string) ([]*busi.EVMTransaction, string, string,
+	*utils.BuErrorResponse) {
+	transactions := make([]*busi.EVMTransaction, 0)
+	next, prev, err := findPage(canonicalSession("evm_transaction").Where(cond), txnKeyset, r, order, &transactions,
+		func(i int) *listCursor {
+			return &listCursor{Height: transactions[i].Height, Index: int64(transactions[i].TransactionIndex)}
+		})
+	if err == errInvalidCursor {
+		return nil, "", "", &utils.BuErrorResponse{HttpCode: http.StatusOK,
+			Response: utils.ErrBlockExplorerAPIServerParams}
 	}
-
-	if err := utils.EngineGroup[utils.TaskDB].Where("\"from\" = ? or \"to\" = ?", address, address).
-		Limit(r.Limit, r.Offset).OrderBy("height desc").Find(rowsSlicePtr); err != nil {
+	if err != nil {
 		log.Errorf("Execute sql error: %v", err)
-		return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+		return nil, "", "", &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
 			Response: utils.ErrBlockExplorerAPIServerInternal}
 	}
 
-	return nil
+	return transactions, next, prev, nil
 }
 
-func evmTransactionCount(address string) (int64, error) {
+func evmTransactionCount(cond builder.Cond) (int64, error) {
 	var (
 		count int64
 		err   error
@@ -111,7 +105,7 @@ func evmTransactionCount(address string) (int64, error) {
 		t busi.EVMTransaction
 	)
 
-	if count, err = utils.EngineGroup[utils.TaskDB].Where("\"from\" = ? or \"to\" = ?", address, address).Count(&t); err != nil {
+	if count, err = canonicalSession("evm_transaction").Where(cond).Count(&t); err != nil {
 		return 0, err
 	}
 
@@ -119,14 +113,25 @@ func evmTransactionCount(address string) (int64, error) {
 }
 
 func parseMethodAndParamsFromContract(input, contractAddress string) (string, string, map[string]interface{}) {
-	if input == "" {
+	return parseMethodAndParams(input, contractAddress, getContractABI)
+}
+
+// parseMethodAndParams decodes input with the ABI abiOf returns for the contract, lists pass the ABIs they loaded
+// in a batch.
+func parseMethodAndParams(input, contractAddress string, abiOf func(string) (*abi.ABI, error)) (string, string,
+	map[string]interface{}) {
+	inputData, err := hex.DecodeString(strings.TrimPrefix(input, "0x"))
+	if err != nil {
 		return "unknown", "", nil
 	}
-	inputData, err := hex.DecodeString(input)
+	// precompiles and native actors take no solidity selector
+	if methodName, methodSig, params, ok := parseBuiltinMethodAndParams(inputData, contractAddress); ok {
+		return methodName, methodSig, params
+	}
 	if len(inputData) < 4 {
 		return "unknown", "", nil
 	}
-	tokenABI, err := getContractABI(contractAddress)
+	tokenABI, err := abiOf(contractAddress)
 	if err != nil {
 		log.Errorf("getContractABI failed, err:%s", err)
 		return fmt.Sprintf("0x%s", hex.EncodeToString(inputData[:4])), "", nil
@@ -167,11 +172,53 @@ func getContractABI(contractAddress string) (*abi.ABI, error) {
 		return nil, nil
 	}
 
+	return cacheContractVerifyABI(&contractVerify)
+}
+
+// getContractABIs returns the ABIs of the verified contracts among addresses, loading the uncached ones in a
+// single query.
+func getContractABIs(addresses []string) (map[string]*abi.ABI, error) {
+	abis := make(map[string]*abi.ABI, len(addresses))
+	missing := make([]string, 0)
+	for _, address := range addresses {
+		address = strings.ToLower(address)
+		if v, ok := cacheABI.Load(address); ok {
+			abis[address] = v.(*abi.ABI)
+		} else {
+			missing = append(missing, address)
+		}
+	}
+	if len(missing) == 0 {
+		return abis, nil
+	}
+
+	var contractVerifies []*busi.EVMContractVerify
+	if err := utils.EngineGroup[utils.APIDB].Where("status=?", busi.EVMContractVerifyStatusSuccessfully).
+		In("address", missing).Find(&contractVerifies); err != nil {
+		return nil, err
+	}
+	for _, contractVerify := range contractVerifies {
+		if _, ok := abis[contractVerify.Address]; ok {
+			continue
+		}
+		tokenABI, err := cacheContractVerifyABI(contractVerify)
+		if err != nil {
+			log.Errorf("parse abi of %s failed, err:%s", contractVerify.Address, err)
+			continue
+		}
+		abis[contractVerify.Address] = tokenABI
+	}
+
+	return abis, nil
+}
+
+// cacheContractVerifyABI parses the ABI out of the compiler output of a verified contract and caches it.
+func cacheContractVerifyABI(contractVerify *busi.EVMContractVerify) (*abi.ABI, error) {
 	var (
 		output    solc.Output
 		abiString string
 	)
-	if err = json.Unmarshal([]byte(contractVerify.Output), &output); err != nil {
+	if err := json.Unmarshal([]byte(contractVerify.Output), &output); err != nil {
 		return nil, err
 	}
 	if contractVerify.CompilerType == busi.CompilerTypeSingleFile {
@@ -188,6 +235,6 @@ func getContractABI(contractAddress string) (*abi.ABI, error) {
 	if err != nil {
 		return nil, err
 	}
-	cacheABI.LoadOrStore(contractAddress, &tokenABI)
+	cacheABI.LoadOrStore(strings.ToLower(contractVerify.Address), &tokenABI)
 	return &tokenABI, nil
 }
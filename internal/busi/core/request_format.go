@@ -2,6 +2,9 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"time"
 
 	"api-server/pkg/models/busi"
 )
@@ -15,7 +18,6 @@ var (
 	ContractBreakDownOrderByTxnsAsc  = 1
 	ContractBreakDownOrderByTxnsDesc = 2
 
-	// TODO next iter
 	ContractBreakDownOrderByInternalTxnsAsc  = 3
 	ContractBreakDownOrderByInternalTxnsDesc = 4
 
@@ -32,14 +34,47 @@ var (
 	ContractBreakDownOrderByCallOutDesc = 12
 )
 
+const (
+	ContractBreakDownWindowAll = "all"
+	ContractBreakDownWindow24h = "24h"
+	ContractBreakDownWindow7d  = "7d"
+	ContractBreakDownWindow30d = "30d"
+)
+
 type ListStatContractBreakdownParams struct {
 	ListQuery
-	OrderBy int `form:"order_by" json:"order_by" binding:"oneof=0 1 2 3 4 5 6 7 8 9 10 11 12"`
+	OrderBy int    `form:"order_by" json:"order_by" binding:"oneof=0 1 2 3 4 5 6 7 8 9 10 11 12"`
+	Window  string `form:"window" json:"window" binding:"omitempty,oneof=all 24h 7d 30d" desc:"all-cumulative, 24h/7d/30d-increase in the window, all by default. user_count is null in a window"`
+	Date    string `form:"date" json:"date" desc:"2006-01-02, the latest stat date by default"`
+}
+
+func (r *ListStatContractBreakdownParams) Validate() error {
+	if err := r.OffsetListValidate(); err != nil {
+		return err
+	}
+
+	if r.Window == "" {
+		r.Window = ContractBreakDownWindowAll
+	}
+	// unique users can't be subtracted between snapshots, they're only ranked cumulatively
+	if r.Window != ContractBreakDownWindowAll &&
+		(r.OrderBy == ContractBreakDownOrderByUserCountAsc || r.OrderBy == ContractBreakDownOrderByUserCountDesc) {
+		return errors.New("the user count can't be ordered by in a window")
+	}
+	if r.Date != "" {
+		if _, err := time.Parse(statDateLayout, r.Date); err != nil {
+			return errors.New("the date should be formatted as 2006-01-02")
+		}
+	}
+
+	return nil
 }
 
 type ListQuery struct {
-	Offset int `form:"o" json:"o"`
-	Limit  int `form:"l" json:"l"`
+	Offset int    `form:"o" json:"o"`
+	Limit  int    `form:"l" json:"l"`
+	Cursor string `form:"cursor" json:"cursor" desc:"next/prev cursor of a previous page of the same list and order, o is ignored, supported by block, txn, internal txn and log lists, rejected by the others"`
+	Total  bool   `form:"total" json:"total" desc:"with a cursor, return an approximate total in hits"`
 }
 
 func (r *ListQuery) ListValidate() error {
@@ -47,6 +82,12 @@ func (r *ListQuery) ListValidate() error {
 		return errors.New("the o(ffset) should be greater than or equal 0")
 	}
 
+	if r.Cursor != "" {
+		if _, err := decodeCursor(r.Cursor); err != nil {
+			return err
+		}
+	}
+
 	switch r.Limit {
 	case 25, 50, 100:
 	default:
@@ -56,6 +97,341 @@ func (r *ListQuery) ListValidate() error {
 	return nil
 }
 
+// OffsetListValidate validates the query of a list paged by offset only, rejecting a cursor.
+func (r *ListQuery) OffsetListValidate() error {
+	if r.Cursor != "" {
+		return errors.New("the cursor isn't supported by this list, page it with o(ffset)")
+	}
+
+	return r.ListValidate()
+}
+
+const (
+	TxnDirectionIn   = "in"
+	TxnDirectionOut  = "out"
+	TxnDirectionSelf = "self"
+
+	TxnStatusSuccess = "success"
+	TxnStatusFailed  = "failed"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+var (
+	selectorRegexp   = regexp.MustCompile(`^0x[0-9a-fA-F]{8}$`)
+	methodNameRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\(.*\))?$`)
+	decimalRegexp    = regexp.MustCompile(`^[0-9]+$`)
+	topicRegexp      = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+)
+
+// TxnFilter filters and orders transaction lists.
+type TxnFilter struct {
+	Direction string `form:"direction" json:"direction" binding:"omitempty,oneof=in out self" desc:"in/out/self, relative to the address, ignored by the txns list"`
+	Method    string `form:"method" json:"method" desc:"0x selector, signature like transfer(address,uint256) or method name of a verified contract"`
+	Status    string `form:"status" json:"status" binding:"omitempty,oneof=success failed"`
+	FromBlock int64  `form:"from_block" json:"from_block"`
+	ToBlock   int64  `form:"to_block" json:"to_block"`
+	FromTime  int64  `form:"from_time" json:"from_time" desc:"unix seconds"`
+	ToTime    int64  `form:"to_time" json:"to_time" desc:"unix seconds"`
+	MinValue  string `form:"min_value" json:"min_value" desc:"attoFIL"`
+	Order     string `form:"order" json:"order" binding:"omitempty,oneof=asc desc" desc:"by height, desc by default"`
+}
+
+func (f *TxnFilter) FilterValidate() error {
+	if f.Order == "" {
+		f.Order = OrderDesc
+	}
+	if f.FromBlock < 0 || f.ToBlock < 0 || (f.ToBlock > 0 && f.FromBlock > f.ToBlock) {
+		return errors.New("the from_block should be less than or equal the to_block")
+	}
+	if f.FromTime < 0 || f.ToTime < 0 || (f.ToTime > 0 && f.FromTime > f.ToTime) {
+		return errors.New("the from_time should be less than or equal the to_time")
+	}
+	if f.MinValue != "" && !decimalRegexp.MatchString(f.MinValue) {
+		return errors.New("the min_value should be an attoFIL integer")
+	}
+	if f.Method != "" && !selectorRegexp.MatchString(f.Method) && !methodNameRegexp.MatchString(f.Method) {
+		return errors.New("the method should be a 0x selector, a signature or a method name")
+	}
+
+	return nil
+}
+
+// IsEmpty tells whether the filter keeps every transaction, whatever the order.
+func (f *TxnFilter) IsEmpty() bool {
+	return f.Direction == "" && f.Method == "" && f.Status == "" && f.FromBlock == 0 && f.ToBlock == 0 &&
+		f.FromTime == 0 && f.ToTime == 0 && f.MinValue == ""
+}
+
+type ListTxnsParams struct {
+	ListQuery
+	TxnFilter
+}
+
+func (r *ListTxnsParams) Validate() error {
+	if err := r.ListValidate(); err != nil {
+		return err
+	}
+
+	return r.FilterValidate()
+}
+
+// LogFilter filters event logs, like eth_getLogs.
+type LogFilter struct {
+	Event     string `form:"event" json:"event" desc:"0x topic0, signature like Transfer(address,address,uint256) or event name of a verified contract"`
+	Topic0    string `form:"topic0" json:"topic0"`
+	Topic1    string `form:"topic1" json:"topic1"`
+	Topic2    string `form:"topic2" json:"topic2"`
+	Topic3    string `form:"topic3" json:"topic3"`
+	FromBlock int64  `form:"from_block" json:"from_block"`
+	ToBlock   int64  `form:"to_block" json:"to_block"`
+	Order     string `form:"order" json:"order" binding:"omitempty,oneof=asc desc" desc:"by height, desc by default"`
+}
+
+func (f *LogFilter) IsEmpty() bool {
+	return f.Event == "" && f.Topic0 == "" && f.Topic1 == "" && f.Topic2 == "" && f.Topic3 == "" &&
+		f.FromBlock == 0 && f.ToBlock == 0
+}
+
+func (f *LogFilter) FilterValidate() error {
+	if f.Order == "" {
+		f.Order = OrderDesc
+	}
+	if f.FromBlock < 0 || f.ToBlock < 0 || (f.ToBlock > 0 && f.FromBlock > f.ToBlock) {
+		return errors.New("the from_block should be less than or equal the to_block")
+	}
+	for _, topic := range []string{f.Topic0, f.Topic1, f.Topic2, f.Topic3} {
+		if topic != "" && !topicRegexp.MatchString(topic) {
+			return errors.New("the topics should be 0x 32 bytes hex")
+		}
+	}
+	if f.Event != "" && !topicRegexp.MatchString(f.Event) && !methodNameRegexp.MatchString(f.Event) {
+		return errors.New("the event should be a 0x topic, a signature or an event name")
+	}
+
+	return nil
+}
+
+type ListLogsParams struct {
+	ListQuery
+	LogFilter
+	Address string `form:"address" json:"address" desc:"emitter, ignored by the contract events"`
+}
+
+func (r *ListLogsParams) Validate() error {
+	if err := r.ListValidate(); err != nil {
+		return err
+	}
+
+	return r.FilterValidate()
+}
+
+type SearchParams struct {
+	Query string `form:"q" json:"q" binding:"required"`
+	Limit int    `form:"l" json:"l" desc:"max candidates, 10 by default"`
+}
+
+func (r *SearchParams) Validate() error {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+	if r.Limit < 0 || r.Limit > 50 {
+		return errors.New("the l(imit) should be between 1 and 50")
+	}
+
+	return nil
+}
+
+const (
+	StatSeriesIntervalDay   = "day"
+	StatSeriesIntervalWeek  = "week"
+	StatSeriesIntervalMonth = "month"
+
+	statDateLayout = "2006-01-02"
+
+	// the default range of a series without from
+	statSeriesDefaultDays = 30
+)
+
+type StatRangeParams struct {
+	From string `form:"from" json:"from" desc:"2006-01-02, 30 days before to by default"`
+	To   string `form:"to" json:"to" desc:"2006-01-02, today by default"`
+}
+
+func (r *StatRangeParams) RangeValidate() error {
+	to := time.Now().UTC()
+	if r.To != "" {
+		t, err := time.Parse(statDateLayout, r.To)
+		if err != nil {
+			return errors.New("the to should be formatted as 2006-01-02")
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -statSeriesDefaultDays)
+	if r.From != "" {
+		f, err := time.Parse(statDateLayout, r.From)
+		if err != nil {
+			return errors.New("the from should be formatted as 2006-01-02")
+		}
+		from = f
+	}
+	if from.After(to) {
+		return errors.New("the from should be before the to")
+	}
+
+	r.From, r.To = from.Format(statDateLayout), to.Format(statDateLayout)
+	return nil
+}
+
+type StatSeriesParams struct {
+	StatRangeParams
+	Interval string `form:"interval" json:"interval" binding:"omitempty,oneof=day week month" desc:"day/week/month, day by default"`
+}
+
+func (r *StatSeriesParams) Validate() error {
+	if r.Interval == "" {
+		r.Interval = StatSeriesIntervalDay
+	}
+
+	return r.RangeValidate()
+}
+
+type GasHistoryParams struct {
+	FromHeight int64 `form:"from_height" json:"from_height" desc:"per block points from this height, with a to_height"`
+	ToHeight   int64 `form:"to_height" json:"to_height" desc:"per block points until this height, at most 2880 blocks"`
+	StatRangeParams
+}
+
+func (r *GasHistoryParams) Validate() error {
+	if r.FromHeight < 0 || r.ToHeight < 0 {
+		return errors.New("the from_height and to_height should be greater than or equal 0")
+	}
+	if r.FromHeight > 0 && r.ToHeight == 0 {
+		return errors.New("the from_height should be given with a to_height")
+	}
+	if r.ToHeight > 0 {
+		return nil
+	}
+
+	return r.RangeValidate()
+}
+
+const (
+	balanceHistoryDefaultPoints = 100
+	balanceHistoryMaxPoints     = 500
+)
+
+type BalanceHistoryParams struct {
+	FromHeight int64 `form:"from_height" json:"from_height" desc:"sample from this height, with a to_height"`
+	ToHeight   int64 `form:"to_height" json:"to_height" desc:"sample until this height, a date range is used without it"`
+	StatRangeParams
+	Points int `form:"points" json:"points" desc:"number of sampled points, 100 by default, at most 500"`
+}
+
+func (r *BalanceHistoryParams) Validate() error {
+	if r.Points == 0 {
+		r.Points = balanceHistoryDefaultPoints
+	}
+	if r.Points < 0 || r.Points > balanceHistoryMaxPoints {
+		return fmt.Errorf("the points should be between 1 and %d", balanceHistoryMaxPoints)
+	}
+	if r.FromHeight < 0 || r.ToHeight < 0 {
+		return errors.New("the from_height and to_height should be greater than or equal 0")
+	}
+	if r.FromHeight > 0 && r.ToHeight == 0 {
+		return errors.New("the from_height should be given with a to_height")
+	}
+	if r.ToHeight > 0 {
+		if r.ToHeight < r.FromHeight {
+			return errors.New("the from_height should be less than or equal the to_height")
+		}
+		return nil
+	}
+
+	return r.RangeValidate()
+}
+
+type BalanceAtParams struct {
+	Height int64 `form:"height" json:"height" desc:"the balance as of this height, the latest by default"`
+}
+
+func (r *BalanceAtParams) Validate() error {
+	if r.Height < 0 {
+		return errors.New("the height should be greater than or equal 0")
+	}
+	return nil
+}
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+type ExportParams struct {
+	Format    string `form:"format" json:"format" binding:"omitempty,oneof=csv ndjson" desc:"csv/ndjson, csv by default"`
+	FromBlock int64  `form:"from_block" json:"from_block"`
+	ToBlock   int64  `form:"to_block" json:"to_block"`
+	From      string `form:"from" json:"from" desc:"2006-01-02, from the first block of the date"`
+	To        string `form:"to" json:"to" desc:"2006-01-02, until the last block of the date"`
+	Limit     int    `form:"limit" json:"limit" desc:"at most this number of rows, the first ones by height; the server's export_max_rows by default and at most. An export missing rows, over the limit or after an error, ends with a {\"truncated\":true,\"reason\":\"limit|error\",\"rows\":n} line in NDJSON, a #truncated,<reason>,<rows> record in CSV"`
+}
+
+func (r *ExportParams) Validate() error {
+	if r.Format == "" {
+		r.Format = ExportFormatCSV
+	}
+	if r.FromBlock < 0 || r.ToBlock < 0 {
+		return errors.New("the from_block and to_block should be greater than or equal 0")
+	}
+	if r.ToBlock > 0 && r.ToBlock < r.FromBlock {
+		return errors.New("the from_block should be less than or equal the to_block")
+	}
+	if r.Limit < 0 {
+		return errors.New("the limit should be greater than or equal 0")
+	}
+
+	var from, to time.Time
+	if r.From != "" {
+		t, err := time.Parse(statDateLayout, r.From)
+		if err != nil {
+			return errors.New("the from should be formatted as 2006-01-02")
+		}
+		from = t
+	}
+	if r.To != "" {
+		t, err := time.Parse(statDateLayout, r.To)
+		if err != nil {
+			return errors.New("the to should be formatted as 2006-01-02")
+		}
+		to = t
+	}
+	if r.From != "" && r.To != "" && from.After(to) {
+		return errors.New("the from should be before the to")
+	}
+
+	return nil
+}
+
+const (
+	GasConsumersWindow24h = "24h"
+	GasConsumersWindow7d  = "7d"
+	GasConsumersWindow30d = "30d"
+)
+
+type ListTopGasConsumersParams struct {
+	ListQuery
+	Window string `form:"window" json:"window" binding:"omitempty,oneof=24h 7d 30d" desc:"24h/7d/30d, 24h by default"`
+}
+
+func (r *ListTopGasConsumersParams) Validate() error {
+	if r.Window == "" {
+		r.Window = GasConsumersWindow24h
+	}
+
+	return r.OffsetListValidate()
+}
+
 type SourceCodePart struct {
 	Filename      string `json:"filename"`
 	SourceCodeUrl string `json:"source_code_url"`
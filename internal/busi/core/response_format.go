@@ -9,22 +9,26 @@ import (
 type ContractsList struct {
 	Contracts []*Contract `json:"contracts"`
 	Hits      int64       `json:"hits"`
+	Exact     bool        `json:"exact" desc:"whether hits is an exact count or an estimate, which also counts the versions superseded by reorgs"`
 }
 
 type StatContractBreakdownDetail struct {
-	Rank            int    `json:"rank"`
-	ContractAddress string `json:"contract_address"`
-	TxnCount        int64  `json:"txn_count"`
-	InternalTxns    int64  `json:"internal_txns"`
-	FilBurned       int64  `json:"fil_burned"`
-	UserCount       int64  `json:"user_count"`
-	CallInCount     int64  `json:"call_in_count"`
-	CallOutCount    int64  `json:"call_out_count"`
+	Rank            int     `json:"rank"`
+	ContractAddress string  `json:"contract_address"`
+	ContractName    string  `json:"contract_name"`
+	TxnCount        int64   `json:"txn_count"`
+	InternalTxns    int64   `json:"internal_txns"`
+	FilBurned       float64 `json:"fil_burned" desc:"FIL"`
+	UserCount       *int64  `json:"user_count" desc:"cumulative unique users, null in a window"`
+	CallInCount     int64   `json:"call_in_count"`
+	CallOutCount    int64   `json:"call_out_count"`
 }
 
 type StatContractBreakdown struct {
 	Contracts []*StatContractBreakdownDetail `json:"contracts"`
 	Hits      int64                          `json:"hits"`
+	StatDate  string                         `json:"stat_date"`
+	Window    string                         `json:"window"`
 }
 
 type Contract struct {
@@ -42,8 +46,11 @@ type Contract struct {
 }
 
 type TxnsList struct {
-	EVMTransaction []*busi.EVMTransaction `json:"evm_txns"`
-	Hits           int64                  `json:"hits"`
+	EVMTransaction []*EVMTransaction `json:"evm_txns"`
+	Hits           int64             `json:"hits"`
+	Exact          bool              `json:"exact" desc:"whether hits is an exact count or an estimate, which also counts the versions superseded by reorgs"`
+	Next           string            `json:"next,omitempty" desc:"cursor of the next page"`
+	Prev           string            `json:"prev,omitempty" desc:"cursor of the previous page"`
 }
 
 const (
@@ -54,14 +61,71 @@ const (
 
 type EVMTransaction struct {
 	busi.EVMTransaction `json:",inline"`
-	ToIsContract        bool  `json:"to_is_contract"`
-	TxnStatus           int   `json:"txn_status"`
-	ConfirmationBlocks  int64 `json:"confirmation_blocks"`
+	ToIsContract        bool   `json:"to_is_contract"`
+	TxnStatus           int    `json:"txn_status"`
+	ConfirmationBlocks  int64  `json:"confirmation_blocks"`
+	Finality            string `json:"finality" desc:"pending/confirmed/finalized"`
+
+	Timestamp         int64  `json:"timestamp"`
+	ValueFIL          string `json:"value_fil"`
+	GasUsed           int64  `json:"gas_used"`
+	BaseFeePerGas     string `json:"base_fee_per_gas"`
+	EffectiveGasPrice string `json:"effective_gas_price"`
+	TxnFee            string `json:"txn_fee" desc:"gas used * effective gas price, attoFIL"`
+	TxnFeeFIL         string `json:"txn_fee_fil"`
+	BurntFee          string `json:"burnt_fee" desc:"gas used * base fee, attoFIL"`
+	BurntFeeFIL       string `json:"burnt_fee_fil"`
+	MinerTip          string `json:"miner_tip" desc:"txn fee - burnt fee, attoFIL"`
+	MinerTipFIL       string `json:"miner_tip_fil"`
+	ContractAddress   string `json:"contract_address,omitempty" desc:"contract created by the txn"`
+}
+
+type Block struct {
+	busi.EVMBlockHeader `json:",inline"`
+	TxnCount            int64  `json:"txn_count"`
+	NullRound           bool   `json:"null_round" desc:"epoch without any block"`
+	ConfirmationBlocks  int64  `json:"confirmation_blocks"`
+	Finality            string `json:"finality" desc:"pending/confirmed/finalized"`
+}
+
+type BlocksList struct {
+	Blocks []*Block `json:"blocks"`
+	Hits   int64    `json:"hits"`
+	Exact  bool     `json:"exact" desc:"whether hits is an exact count or an estimate, which also counts the versions superseded by reorgs"`
+	Next   string   `json:"next,omitempty" desc:"cursor of the next page"`
+	Prev   string   `json:"prev,omitempty" desc:"cursor of the previous page"`
 }
 
 type InternalTxnsList struct {
 	EVMInternalTX []*busi.EVMInternalTX `json:"evm_internal_txns"`
 	Hits          int64                 `json:"hits"`
+	Exact         bool                  `json:"exact" desc:"whether hits is an exact count or an estimate, which also counts the versions superseded by reorgs"`
+	Next          string                `json:"next,omitempty" desc:"cursor of the next page"`
+	Prev          string                `json:"prev,omitempty" desc:"cursor of the previous page"`
+}
+
+type TxnTrace struct {
+	Hash           string     `json:"hash"`
+	Status         int        `json:"status" desc:"1 success, 0 failed, -1 pending"`
+	Calls          int64      `json:"calls" desc:"number of internal calls"`
+	ValueTransfers int64      `json:"value_transfers" desc:"number of calls transferring FIL, the txn included"`
+	Root           *TraceCall `json:"root" desc:"the txn itself"`
+	Inferred       bool       `json:"inferred" desc:"the nesting and the order of sibling calls are inferred, the call stack isn't recorded"`
+}
+
+type TraceCall struct {
+	Type          string       `json:"type" desc:"call/delegatecall/staticcall/create/create2, type N for an unknown type_code"`
+	TypeCode      uint64       `json:"type_code" desc:"evm_internal_tx.type as ingested"`
+	Hash          string       `json:"hash"`
+	From          string       `json:"from"`
+	To            string       `json:"to"`
+	Value         string       `json:"value" desc:"attoFIL"`
+	ValueFIL      string       `json:"value_fil"`
+	ValueTransfer bool         `json:"value_transfer" desc:"whether the call transfers FIL"`
+	MethodName    string       `json:"method_name" desc:"of the txn and of the calls into builtin actors, internal calls have no input to decode"`
+	ContractName  string       `json:"contract_name" desc:"of the callee, if verified"`
+	Depth         int          `json:"depth"`
+	Calls         []*TraceCall `json:"calls"`
 }
 
 type CompileVersionList struct {
@@ -101,6 +165,33 @@ type ContractDetail struct {
 	Verified        time.Time     `json:"verified"`
 	ABI             string        `json:"abi"`
 	SourceCodes     []*SourceCode `json:"source_codes"`
+	ContractDestruction
+}
+
+type ContractDestruction struct {
+	Destroyed       bool  `json:"destroyed" desc:"the bytecode was emptied, e.g. by a selfdestruct"`
+	DestroyedHeight int64 `json:"destroyed_height"`
+	DestroyedTime   int64 `json:"destroyed_time" desc:"unix seconds"`
+}
+
+type ContractChange struct {
+	Height         int64  `json:"height"`
+	Timestamp      int64  `json:"timestamp"`
+	Balance        string `json:"balance" desc:"attoFIL"`
+	BalanceFIL     string `json:"balance_fil"`
+	Nonce          uint64 `json:"nonce"`
+	CodeChanged    bool   `json:"code_changed" desc:"the bytecode differs from the previous state, always so for the first one"`
+	BalanceChanged bool   `json:"balance_changed"`
+	CodeSize       int    `json:"code_size" desc:"bytes"`
+	ByteCode       string `json:"byte_code,omitempty" desc:"only when the bytecode changed"`
+	Destroyed      bool   `json:"destroyed" desc:"the bytecode is empty after an earlier state had some"`
+}
+
+type ContractHistory struct {
+	Address string `json:"address"`
+	ContractDestruction
+	Changes []*ContractChange `json:"changes"`
+	Hits    int64             `json:"hits"`
 }
 
 type ContractIsVerify struct {
@@ -114,14 +205,63 @@ type ContractIsContract struct {
 type Address struct {
 	Height          int64  `json:"height"`
 	Address         string `json:"address"`
+	EthAddress      string `json:"eth_address"`
 	FilecoinAddress string `json:"filecoin_address"`
 	Balance         string `json:"balance"`
 	Nonce           uint64 `json:"nonce"`
+
+	IsContract    bool            `json:"is_contract"`
+	Label         string          `json:"label"`
+	LabelCategory string          `json:"label_category"`
+	TokenHoldings []*TokenHolding `json:"token_holdings"`
+	AddressSummary
+}
+
+type AddressSummary struct {
+	TxnCount         int64  `json:"txn_count"`
+	InternalTxnCount int64  `json:"internal_txn_count"`
+	FirstSeenHeight  int64  `json:"first_seen_height"`
+	FirstSeenTime    int64  `json:"first_seen_time" desc:"unix seconds"`
+	LastSeenHeight   int64  `json:"last_seen_height"`
+	LastSeenTime     int64  `json:"last_seen_time" desc:"unix seconds"`
+	Creator          string `json:"creator" desc:"of a contract"`
+	CreationTxn      string `json:"creation_txn" desc:"of a contract"`
+	SummarizedHeight int64  `json:"summarized_height" desc:"the activity up to it is read from the summary, above it counted on the fly"`
+}
+
+type BalancePoint struct {
+	Height      int64   `json:"height"`
+	Timestamp   int64   `json:"timestamp" desc:"of the block at or below the height, unix seconds"`
+	StateHeight int64   `json:"state_height" desc:"the height the balance was last changed at, 0 before the address existed"`
+	Balance     string  `json:"balance" desc:"attoFIL"`
+	BalanceFIL  string  `json:"balance_fil"`
+	BalanceUSD  float64 `json:"balance_usd" desc:"at the fil2usd rate of the day, 0 when there is none yet"`
+}
+
+type AddressBalance struct {
+	Address string `json:"address"`
+	BalancePoint
+}
+
+type BalanceHistory struct {
+	Address    string          `json:"address"`
+	FromHeight int64           `json:"from_height"`
+	ToHeight   int64           `json:"to_height"`
+	Points     []*BalancePoint `json:"points"`
+}
+
+type TokenHolding struct {
+	Token     string `json:"token"`
+	TokenType string `json:"token_type" desc:"erc20/erc721, told by the Transfer logs"`
+	Balance   string `json:"balance" desc:"in the token's smallest unit, or the number of erc721 tokens"`
 }
 
 type EventList struct {
-	Hits   int      `json:"hits"`
+	Hits   int64    `json:"hits"`
+	Exact  bool     `json:"exact" desc:"whether hits is an exact count or an estimate, which also counts the versions superseded by reorgs"`
 	Events []*Event `json:"events"`
+	Next   string   `json:"next,omitempty" desc:"cursor of the next page"`
+	Prev   string   `json:"prev,omitempty" desc:"cursor of the previous page"`
 }
 
 type Event struct {
@@ -143,6 +283,19 @@ type SearchTextType struct {
 	Type string `json:"type"`
 }
 
+type SearchCandidate struct {
+	Type            string `json:"type" desc:"address/contract/txn/block/label"`
+	Value           string `json:"value" desc:"address, txn hash or block height"`
+	Name            string `json:"name"`
+	FilecoinAddress string `json:"filecoin_address,omitempty"`
+	Score           int    `json:"score"`
+}
+
+type SearchResult struct {
+	Query      string             `json:"query"`
+	Candidates []*SearchCandidate `json:"candidates"`
+}
+
 type StatOverview struct {
 	TotalInternalTxnCount     int64   `json:"total_internal_txn_count"`
 	TotalExternalTxnCount     int64   `json:"total_external_txn_count"`
@@ -152,3 +305,160 @@ type StatOverview struct {
 	TotalContractCount        int64   `json:"total_contract_count"`
 	TotalDeployerAddressCount int64   `json:"total_deployer_address_count"`
 }
+
+type StatSeriesPoint struct {
+	Date  string  `json:"date" desc:"first day of the interval"`
+	Value float64 `json:"value"`
+}
+
+type StatSeries struct {
+	Metric   string             `json:"metric"`
+	Interval string             `json:"interval"`
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Points   []*StatSeriesPoint `json:"points"`
+}
+
+type StatActiveUsers struct {
+	StatDate string `json:"stat_date"`
+	Daily    int64  `json:"daily"`
+	Weekly   int64  `json:"weekly"`
+	Monthly  int64  `json:"monthly"`
+}
+
+type StatActiveUsersHistory struct {
+	Latest  *StatActiveUsers   `json:"latest"`
+	History []*StatActiveUsers `json:"history"`
+}
+
+type StatContractActivity struct {
+	StatDate      string `json:"stat_date"`
+	NewDaily      int64  `json:"new_daily"`
+	NewWeekly     int64  `json:"new_weekly"`
+	NewMonthly    int64  `json:"new_monthly"`
+	ActiveDaily   int64  `json:"active_daily"`
+	ActiveWeekly  int64  `json:"active_weekly"`
+	ActiveMonthly int64  `json:"active_monthly"`
+}
+
+type StatContractActivityHistory struct {
+	Latest  *StatContractActivity   `json:"latest"`
+	History []*StatContractActivity `json:"history"`
+}
+
+type ContractStatsPoint struct {
+	Date         string  `json:"date"`
+	TxnCount     int64   `json:"txn_count" desc:"cumulative until the day"`
+	UserCount    int64   `json:"user_count" desc:"cumulative until the day"`
+	CallInCount  int64   `json:"call_in_count" desc:"cumulative until the day"`
+	CallOutCount int64   `json:"call_out_count" desc:"cumulative until the day"`
+	ValueIn      float64 `json:"value_in" desc:"FIL received on the day"`
+	ValueOut     float64 `json:"value_out" desc:"FIL sent on the day"`
+}
+
+type ContractStats struct {
+	Address string                `json:"address"`
+	From    string                `json:"from"`
+	To      string                `json:"to"`
+	Points  []*ContractStatsPoint `json:"points"`
+}
+
+type StatTableStatus struct {
+	Table          string `json:"table"`
+	LatestStatDate string `json:"latest_stat_date"`
+	LagDays        int    `json:"lag_days" desc:"days behind today (UTC)"`
+}
+
+type ChainHeadStatus struct {
+	Height    int64 `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+	Lag       int64 `json:"lag" desc:"seconds behind wall clock"`
+}
+
+type StatStatus struct {
+	ChainHead  ChainHeadStatus    `json:"chain_head"`
+	StatTables []*StatTableStatus `json:"stat_tables"`
+	Ready      bool               `json:"ready"`
+	Reasons    []string           `json:"reasons"`
+}
+
+type TopAccount struct {
+	Rank            int     `json:"rank"`
+	Address         string  `json:"address"`
+	FilecoinAddress string  `json:"filecoin_address"`
+	Balance         string  `json:"balance"`
+	Share           float64 `json:"share" desc:"share of the total balance of all accounts"`
+	IsContract      bool    `json:"is_contract"`
+}
+
+type TopAccountsList struct {
+	Accounts     []*TopAccount `json:"accounts"`
+	Hits         int64         `json:"hits"`
+	TotalBalance string        `json:"total_balance"`
+}
+
+type TopDeployer struct {
+	Rank               int    `json:"rank"`
+	Address            string `json:"address"`
+	FilecoinAddress    string `json:"filecoin_address"`
+	ContractCount      int64  `json:"contract_count"`
+	LastDeployedHeight int64  `json:"last_deployed_height"`
+}
+
+type TopDeployersList struct {
+	Deployers []*TopDeployer `json:"deployers"`
+	Hits      int64          `json:"hits"`
+}
+
+type GasPriorityFee struct {
+	Low    string `json:"low" desc:"25th percentile"`
+	Medium string `json:"medium" desc:"50th percentile"`
+	High   string `json:"high" desc:"75th percentile"`
+}
+
+type GasTracker struct {
+	Height         int64          `json:"height"`
+	Timestamp      int64          `json:"timestamp"`
+	BaseFeePerGas  string         `json:"base_fee_per_gas"`
+	GasUsed        int64          `json:"gas_used"`
+	GasLimit       int64          `json:"gas_limit"`
+	Utilization    float64        `json:"utilization" desc:"gas used / gas limit of the latest block"`
+	PriorityFee    GasPriorityFee `json:"priority_fee" desc:"suggested from the txns of the recent blocks"`
+	AvgUtilization float64        `json:"avg_utilization" desc:"of the recent blocks"`
+	Blocks         int            `json:"blocks" desc:"number of recent blocks"`
+}
+
+type GasHistoryPoint struct {
+	Height        int64   `json:"height,omitempty"`
+	Timestamp     int64   `json:"timestamp,omitempty"`
+	Date          string  `json:"date,omitempty"`
+	BaseFeePerGas string  `json:"base_fee_per_gas" desc:"daily average by date"`
+	GasUsed       int64   `json:"gas_used"`
+	GasLimit      int64   `json:"gas_limit"`
+	Utilization   float64 `json:"utilization" desc:"daily average by date"`
+}
+
+type GasHistory struct {
+	Points []*GasHistoryPoint `json:"points"`
+}
+
+type GasConsumer struct {
+	Rank            int    `json:"rank"`
+	Address         string `json:"address"`
+	FilecoinAddress string `json:"filecoin_address"`
+	TxnCount        int64  `json:"txn_count"`
+	GasUsed         int64  `json:"gas_used"`
+	GasFee          string `json:"gas_fee" desc:"gas used * effective gas price, attoFIL"`
+}
+
+type GasConsumersList struct {
+	Consumers []*GasConsumer `json:"consumers"`
+	Hits      int64          `json:"hits"`
+	Window    string         `json:"window"`
+}
+
+type RowVersions struct {
+	Table string              `json:"table"`
+	Keys  []string            `json:"keys"`
+	Rows  []map[string]string `json:"rows"`
+}
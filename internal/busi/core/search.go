@@ -0,0 +1,254 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+	"xorm.io/builder"
+)
+
+const (
+	SearchTypeAddress  = "address"
+	SearchTypeContract = "contract"
+	SearchTypeTxn      = "txn"
+	SearchTypeBlock    = "block"
+	SearchTypeLabel    = "label"
+
+	searchScoreExact  = 100
+	searchScorePrefix = 50
+
+	// shorter hex prefixes match too many rows to be useful
+	searchMinHexPrefixLength = 6
+)
+
+var (
+	hexPrefixRegexp = regexp.MustCompile(`^0x[0-9a-f]*$`)
+	hashRegexp      = regexp.MustCompile(`^0x[0-9a-f]{64}$`)
+)
+
+// Search returns the ranked candidates of q: exact matches of an address (any form), contract, txn hash,
+// block height or hash first, then prefix matches of addresses, hashes, contract names and labels.
+func Search(ctx context.Context, r *SearchParams) (interface{}, *utils.BuErrorResponse) {
+	q := strings.TrimSpace(r.Query)
+	lower := strings.ToLower(q)
+
+	s := &searcher{limit: r.Limit, seen: make(map[string]bool)}
+
+	// block height
+	if height, err := strconv.ParseInt(q, 10, 64); err == nil && height >= 0 {
+		if err := s.searchBlockHeight(height); err != nil {
+			return nil, searchError(err)
+		}
+	}
+
+	// address in any of its forms
+	if address, err := utils.NormalizeAddress(q); err == nil {
+		if err := s.searchAddress(address); err != nil {
+			return nil, searchError(err)
+		}
+	}
+
+	// txn or block hash, exact or prefix
+	if hashRegexp.MatchString(lower) {
+		if err := s.searchHash(lower, false); err != nil {
+			return nil, searchError(err)
+		}
+	} else if hexPrefixRegexp.MatchString(lower) && len(lower) >= searchMinHexPrefixLength {
+		if err := s.searchHexPrefix(lower); err != nil {
+			return nil, searchError(err)
+		}
+	}
+
+	// names
+	if !hexPrefixRegexp.MatchString(lower) {
+		if err := s.searchNames(lower); err != nil {
+			return nil, searchError(err)
+		}
+	}
+
+	sort.SliceStable(s.candidates, func(i, j int) bool {
+		return s.candidates[i].Score > s.candidates[j].Score
+	})
+	if len(s.candidates) > r.Limit {
+		s.candidates = s.candidates[:r.Limit]
+	}
+
+	return SearchResult{Query: q, Candidates: s.candidates}, nil
+}
+
+func searchError(err error) *utils.BuErrorResponse {
+	log.Errorf("Execute sql error: %v", err)
+	return &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+		Response: utils.ErrBlockExplorerAPIServerInternal}
+}
+
+type searcher struct {
+	limit      int
+	seen       map[string]bool
+	candidates []*SearchCandidate
+}
+
+func (s *searcher) add(c *SearchCandidate) {
+	key := c.Type + ":" + c.Value
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+	s.candidates = append(s.candidates, c)
+}
+
+func (s *searcher) searchBlockHeight(height int64) error {
+	block, buErr := getBlock("height = ?", height)
+	if buErr != nil {
+		return buErr.Response
+	}
+	if block != nil {
+		s.add(&SearchCandidate{Type: SearchTypeBlock, Value: strconv.FormatInt(height, 10), Score: searchScoreExact})
+		return nil
+	}
+
+	// null rounds are still valid epochs
+	head, err := chainHead.get()
+	if err != nil {
+		return err
+	}
+	if height < head.Height {
+		s.add(&SearchCandidate{Type: SearchTypeBlock, Value: strconv.FormatInt(height, 10), Name: "null round",
+			Score: searchScoreExact})
+	}
+	return nil
+}
+
+func (s *searcher) searchAddress(address *utils.Address) error {
+	values := make([]interface{}, 0)
+	for _, v := range address.QueryValues() {
+		values = append(values, v)
+	}
+	cond := builder.Or(builder.In("address", values...), builder.In("filecoin_address", values...))
+
+	var contracts []*busi.EVMContract
+	if err := canonicalSession("evm_contract").Where(cond).Limit(s.limit).Find(&contracts); err != nil {
+		return err
+	}
+	for _, contract := range contracts {
+		s.add(&SearchCandidate{Type: SearchTypeContract, Value: contract.Address, Score: searchScoreExact,
+			FilecoinAddress: filecoinAddressOf(contract.Address, contract.FilecoinAddress)})
+	}
+
+	var addresses []*busi.EVMAddress
+	if err := canonicalSession("evm_address").Where(cond).Limit(s.limit).Find(&addresses); err != nil {
+		return err
+	}
+	for _, evmAddress := range addresses {
+		if s.seen[SearchTypeContract+":"+evmAddress.Address] {
+			continue
+		}
+		s.add(&SearchCandidate{Type: SearchTypeAddress, Value: evmAddress.Address, Score: searchScoreExact,
+			FilecoinAddress: filecoinAddressOf(evmAddress.Address, evmAddress.FilecoinAddress)})
+	}
+
+	return nil
+}
+
+func (s *searcher) searchHash(hash string, prefix bool) error {
+	cond, score := builder.Expr("hash = ?", hash), searchScoreExact
+	if prefix {
+		cond, score = builder.Like{"hash", hash + "%"}, searchScorePrefix
+	}
+
+	txns, err := canonicalSession("evm_transaction").Cols("hash").Where(cond).Limit(s.limit).QueryString()
+	if err != nil {
+		return err
+	}
+	for _, txn := range txns {
+		s.add(&SearchCandidate{Type: SearchTypeTxn, Value: txn["hash"], Score: score})
+	}
+
+	blocks, err := canonicalSession("evm_block_header").Cols("height", "hash").Where(cond).Limit(s.limit).QueryString()
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		s.add(&SearchCandidate{Type: SearchTypeBlock, Value: block["height"], Name: block["hash"], Score: score})
+	}
+
+	return nil
+}
+
+func (s *searcher) searchHexPrefix(prefix string) error {
+	like := builder.Like{"address", prefix + "%"}
+
+	contracts, err := canonicalSession("evm_contract").Cols("address", "filecoin_address").Where(like).
+		Limit(s.limit).QueryString()
+	if err != nil {
+		return err
+	}
+	for _, contract := range contracts {
+		s.add(&SearchCandidate{Type: SearchTypeContract, Value: contract["address"], Score: searchScorePrefix,
+			FilecoinAddress: filecoinAddressOf(contract["address"], contract["filecoin_address"])})
+	}
+
+	addresses, err := canonicalSession("evm_address").Cols("address", "filecoin_address").Where(like).
+		Limit(s.limit).QueryString()
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if s.seen[SearchTypeContract+":"+address["address"]] {
+			continue
+		}
+		s.add(&SearchCandidate{Type: SearchTypeAddress, Value: address["address"], Score: searchScorePrefix,
+			FilecoinAddress: filecoinAddressOf(address["address"], address["filecoin_address"])})
+	}
+
+	return s.searchHash(prefix, true)
+}
+
+// searchNames prefix matches verified contract names and labels, exact matches rank first.
+func (s *searcher) searchNames(text string) error {
+	like := escapeLike(text) + "%"
+
+	var contractVerifies []*busi.EVMContractVerify
+	if err := utils.EngineGroup[utils.APIDB].Cols("address", "contract_name").
+		Where("status = ? and lower(contract_name) like ?", busi.EVMContractVerifyStatusSuccessfully, like).
+		Limit(s.limit).Find(&contractVerifies); err != nil {
+		return err
+	}
+	for _, cv := range contractVerifies {
+		s.add(&SearchCandidate{Type: SearchTypeContract, Value: cv.Address, Name: cv.ContractName,
+			Score: nameScore(text, cv.ContractName), FilecoinAddress: filecoinAddressOf(cv.Address, "")})
+	}
+
+	var labels []*busi.EVMAddressLabel
+	if err := utils.EngineGroup[utils.APIDB].Where("lower(label) like ?", like).
+		Limit(s.limit).Find(&labels); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		s.add(&SearchCandidate{Type: SearchTypeLabel, Value: label.Address, Name: label.Label,
+			Score: nameScore(text, label.Label), FilecoinAddress: filecoinAddressOf(label.Address, "")})
+	}
+
+	return nil
+}
+
+func nameScore(text, name string) int {
+	if strings.ToLower(name) == text {
+		return searchScoreExact
+	}
+	return searchScorePrefix
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"net/http"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	contractCountDailyCols = "stat_date, sum(new_contract_count_daily) as new_contract_count_daily, " +
+		"sum(new_contract_count_weekly) as new_contract_count_weekly, " +
+		"sum(new_contract_count_monthly) as new_contract_count_monthly, " +
+		"sum(active_contract_count_daily) as active_contract_count_daily, " +
+		"sum(active_contract_count_weekly) as active_contract_count_weekly, " +
+		"sum(active_contract_count_monthly) as active_contract_count_monthly"
+)
+
+// GetStatActiveUsers returns the daily/weekly/monthly active users between r.From and r.To, and those of the last
+// ready stat date.
+func GetStatActiveUsers(ctx context.Context, r *StatRangeParams) (interface{}, *utils.BuErrorResponse) {
+	lastDate, err := getStatDBLastStatDate("fvm_user_count_daily")
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	var fvmUserCountDailys []*busi.FVMUserCountDaily
+	if err = utils.EngineGroup[utils.StatDB].Where("stat_date >= ? and stat_date <= ? and stat_date <= ?",
+		r.From, r.To, lastDate).Asc("stat_date").Find(&fvmUserCountDailys); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	var (
+		latest busi.FVMUserCountDaily
+		resp   = StatActiveUsersHistory{History: make([]*StatActiveUsers, 0, len(fvmUserCountDailys))}
+	)
+	for _, fvmUserCountDaily := range fvmUserCountDailys {
+		resp.History = append(resp.History, newStatActiveUsers(fvmUserCountDaily))
+	}
+
+	exist, err := utils.EngineGroup[utils.StatDB].Where("stat_date=?", lastDate).Get(&latest)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if exist {
+		resp.Latest = newStatActiveUsers(&latest)
+	}
+
+	return resp, nil
+}
+
+func newStatActiveUsers(f *busi.FVMUserCountDaily) *StatActiveUsers {
+	return &StatActiveUsers{
+		StatDate: f.StatDate.Format("2006-01-02"),
+		Daily:    f.ActiveUserCountDaily,
+		Weekly:   f.ActiveUserCountWeekly,
+		Monthly:  f.ActiveUserCountMonthly,
+	}
+}
+
+// GetStatContractActivity returns the new and active contracts between r.From and r.To, and those of the last
+// ready stat date. Rows of a stat date are summed up as they may be broken down by contract.
+func GetStatContractActivity(ctx context.Context, r *StatRangeParams) (interface{}, *utils.BuErrorResponse) {
+	lastDate, err := getStatDBLastStatDate("fvm_contract_count_daily")
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	var fvmContractCountDailys []*busi.FVMContractCountDaily
+	if err = utils.EngineGroup[utils.StatDB].Select(contractCountDailyCols).
+		Where("stat_date >= ? and stat_date <= ? and stat_date <= ?", r.From, r.To, lastDate).
+		GroupBy("stat_date").Asc("stat_date").Find(&fvmContractCountDailys); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	resp := StatContractActivityHistory{History: make([]*StatContractActivity, 0, len(fvmContractCountDailys))}
+	for _, fvmContractCountDaily := range fvmContractCountDailys {
+		resp.History = append(resp.History, newStatContractActivity(fvmContractCountDaily))
+	}
+
+	var latest []*busi.FVMContractCountDaily
+	if err = utils.EngineGroup[utils.StatDB].Select(contractCountDailyCols).Where("stat_date=?", lastDate).
+		GroupBy("stat_date").Find(&latest); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if len(latest) > 0 {
+		resp.Latest = newStatContractActivity(latest[0])
+	}
+
+	return resp, nil
+}
+
+func newStatContractActivity(f *busi.FVMContractCountDaily) *StatContractActivity {
+	return &StatContractActivity{
+		StatDate:      f.StatDate.Format("2006-01-02"),
+		NewDaily:      int64(f.NewContractCountDaily),
+		NewWeekly:     int64(f.NewContractCountWeekly),
+		NewMonthly:    int64(f.NewContractCountMonthly),
+		ActiveDaily:   int64(f.ActiveContractCountDaily),
+		ActiveWeekly:  int64(f.ActiveContractCountWeekly),
+		ActiveMonthly: int64(f.ActiveContractCountMonthly),
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type statSeriesMetric struct {
+	table  string
+	column string
+}
+
+// statSeriesMetrics the charted metrics, all of them are cumulative totals or levels of the stat day.
+var statSeriesMetrics = map[string]statSeriesMetric{
+	"contract_count":         {"fvm_summary_daily", "total_contract_count"},
+	"internal_txn_count":     {"fvm_summary_daily", "total_internal_txn_count"},
+	"external_txn_count":     {"fvm_summary_daily", "total_external_txn_count"},
+	"txn_count":              {"fvm_summary_daily", "total_txn_count"},
+	"deployer_address_count": {"fvm_summary_daily", "total_deployer_address_count"},
+	"network_address_count":  {"fvm_summary_daily", "total_network_address_count"},
+	"tvl":                    {"fvm_total_value_locked_daily", "total_value_locked"},
+	"tvl_usd":                {"fvm_total_value_locked_daily", "total_value_locked_usd"},
+}
+
+// GetStatSeries returns the daily history of a metric between r.From and r.To. Weekly and monthly points hold the
+// value of the last stat day of the week/month, as every metric is a total rather than a daily delta.
+func GetStatSeries(ctx context.Context, metric string, r *StatSeriesParams) (interface{}, *utils.BuErrorResponse) {
+	m, ok := statSeriesMetrics[metric]
+	if !ok {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerParams}
+	}
+
+	bucket := "stat_date"
+	if r.Interval != StatSeriesIntervalDay {
+		bucket = fmt.Sprintf("date_trunc('%s', stat_date)", r.Interval)
+	}
+
+	rows, err := utils.EngineGroup[utils.StatDB].SQL(fmt.Sprintf(`
+select distinct on (%s) to_char(%s, 'YYYY-MM-DD') as date, %s as value from %s
+where stat_date >= ? and stat_date <= ?
+order by %s, stat_date desc`, bucket, bucket, m.column, m.table, bucket), r.From, r.To).QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	series := StatSeries{Metric: metric, Interval: r.Interval, From: r.From, To: r.To,
+		Points: make([]*StatSeriesPoint, 0, len(rows))}
+	for _, row := range rows {
+		value, err := strconv.ParseFloat(row["value"], 64)
+		if err != nil {
+			log.Errorf("parse %s of %s error: %v", metric, row["date"], err)
+			return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+				Response: utils.ErrBlockExplorerAPIServerInternal}
+		}
+		series.Points = append(series.Points, &StatSeriesPoint{Date: row["date"], Value: value})
+	}
+
+	return series, nil
+}
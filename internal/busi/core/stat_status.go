@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// statTablesInSync the stat tables read together, which must be ready up to the same date.
+var statTablesInSync = []string{"fvm_contract_summary_daily", "fvm_contract_call_count_daily"}
+
+// GetStatStatus reports the freshness of the stat tables and of the task_db chain head, and whether both are
+// within the configured lags.
+func GetStatStatus(ctx context.Context) (*StatStatus, *utils.BuErrorResponse) {
+	status := &StatStatus{Ready: true, Reasons: make([]string, 0)}
+	notReady := func(format string, args ...interface{}) {
+		status.Ready = false
+		status.Reasons = append(status.Reasons, fmt.Sprintf(format, args...))
+	}
+
+	now := time.Now().UTC()
+
+	head, err := chainHead.refresh()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	status.ChainHead = ChainHeadStatus{Height: head.Height, Timestamp: head.Timestamp, Lag: now.Unix() - head.Timestamp}
+	if status.ChainHead.Lag > utils.CNF.APIServer.MaxChainHeadLag {
+		notReady("chain head %d is %ds behind", head.Height, status.ChainHead.Lag)
+	}
+
+	var readies []*busi.FVMStatDataIsReady
+	if err = utils.EngineGroup[utils.StatDB].Asc("table_name").Find(&readies); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	latestStatDates := make(map[string]string, len(readies))
+	for _, ready := range readies {
+		table := strings.TrimPrefix(ready.Tablename, "ft.")
+		latest := ready.LatestStatDate.UTC()
+		tableStatus := &StatTableStatus{
+			Table:          table,
+			LatestStatDate: latest.Format(statDateLayout),
+			LagDays:        int(today.Sub(time.Date(latest.Year(), latest.Month(), latest.Day(), 0, 0, 0, 0, time.UTC)).Hours() / 24),
+		}
+		if tableStatus.LagDays > utils.CNF.APIServer.MaxStatDateLag {
+			notReady("%s is %d days behind", table, tableStatus.LagDays)
+		}
+		latestStatDates[table] = tableStatus.LatestStatDate
+		status.StatTables = append(status.StatTables, tableStatus)
+	}
+
+	for _, table := range statTablesInSync {
+		if latestStatDates[table] != latestStatDates[statTablesInSync[0]] {
+			notReady("%s and %s are not ready up to the same date", statTablesInSync[0], table)
+		}
+	}
+
+	return status, nil
+}
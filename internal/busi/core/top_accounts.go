@@ -0,0 +1,182 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// accountTotalsTTL how long the count and total balance of all accounts are reused, they need a full scan
+const accountTotalsTTL = 10 * time.Minute
+
+type accountTotalsValues struct {
+	count     int64
+	balance   string
+	share     *big.Float
+	updatedAt time.Time
+}
+
+// accountTotals the count and total balance of all accounts, refreshed by a single scan at a time. Stale values are
+// served while the scan runs, only the first requests wait for it.
+type accountTotals struct {
+	mu     sync.Mutex
+	values *accountTotalsValues
+	err    error
+	// refreshing closed when the running scan ends, nil when none is running
+	refreshing chan struct{}
+}
+
+var accountTotalsOf = &accountTotals{}
+
+// get returns the number of accounts (addresses and contracts) and the sum of their balances, exact and as the
+// divisor of shares.
+func (t *accountTotals) get() (int64, string, *big.Float, error) {
+	t.mu.Lock()
+	values, refreshing := t.values, t.refreshing
+	if refreshing == nil && (values == nil || time.Since(values.updatedAt) >= accountTotalsTTL) {
+		refreshing = make(chan struct{})
+		t.refreshing = refreshing
+		go t.refresh(refreshing)
+	}
+	t.mu.Unlock()
+
+	if values == nil {
+		<-refreshing
+
+		t.mu.Lock()
+		values, err := t.values, t.err
+		t.mu.Unlock()
+		if values == nil {
+			return 0, "", nil, err
+		}
+		return values.count, values.balance, values.share, nil
+	}
+
+	return values.count, values.balance, values.share, nil
+}
+
+func (t *accountTotals) refresh(done chan struct{}) {
+	values, err := scanAccountTotals()
+
+	t.mu.Lock()
+	if err != nil {
+		log.Errorf("scan account totals error: %v", err)
+	} else {
+		t.values = values
+	}
+	t.err, t.refreshing = err, nil
+	t.mu.Unlock()
+
+	close(done)
+}
+
+func scanAccountTotals() (*accountTotalsValues, error) {
+	result, err := utils.EngineGroup[utils.TaskDB].SQL(fmt.Sprintf(`
+select count(*) as count, coalesce(sum(balance), 0) as balance from (%s) t`, topAccountsSQL)).QueryString()
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.ParseInt(result[0]["count"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	share, ok := new(big.Float).SetString(result[0]["balance"])
+	if !ok {
+		return nil, fmt.Errorf("invalid total balance %s", result[0]["balance"])
+	}
+
+	return &accountTotalsValues{count: count, balance: result[0]["balance"], share: share, updatedAt: time.Now()},
+		nil
+}
+
+// topAccountsSQL the canonical balances of addresses and contracts, the numeric balance is indexed on both tables
+// so that ordering by it stops after the requested rows.
+var topAccountsSQL = fmt.Sprintf(`
+select address, filecoin_address, balance::numeric as balance, false as is_contract from evm_address
+  where %s and not exists (select 1 from evm_contract c where c.address = evm_address.address)
+union all
+select address, filecoin_address, balance::numeric as balance, true as is_contract from evm_contract
+  where %s`, canonicalCond("evm_address"), canonicalCond("evm_contract"))
+
+// ListTopAccounts ranks addresses and contracts by their latest balance, with their share of the total balance.
+func ListTopAccounts(ctx context.Context, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+	hits, totalBalance, total, err := accountTotalsOf.get()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	rows, err := utils.EngineGroup[utils.TaskDB].SQL(topAccountsSQL+`
+order by balance desc, address
+limit ? offset ?`, r.Limit, r.Offset).QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	topAccounts := TopAccountsList{Accounts: make([]*TopAccount, 0, len(rows)), Hits: hits,
+		TotalBalance: totalBalance}
+	for index, row := range rows {
+		account := &TopAccount{
+			Rank:            r.Offset + 1 + index,
+			Address:         row["address"],
+			FilecoinAddress: filecoinAddressOf(row["address"], row["filecoin_address"]),
+			Balance:         row["balance"],
+			IsContract:      row["is_contract"] == "true",
+		}
+		if balance, ok := new(big.Float).SetString(row["balance"]); ok && total.Sign() > 0 {
+			account.Share, _ = new(big.Float).Quo(balance, total).Float64()
+		}
+		topAccounts.Accounts = append(topAccounts.Accounts, account)
+	}
+
+	return topAccounts, nil
+}
+
+// ListTopDeployers ranks addresses by the number of contracts they created, a contract creation being a receipt
+// without to, the one findCreatorTransaction finds for a contract.
+func ListTopDeployers(ctx context.Context, r *ListQuery) (interface{}, *utils.BuErrorResponse) {
+	deployersSQL := fmt.Sprintf(`
+select "from" as address, count(*) as contract_count, max(height) as last_deployed_height from evm_receipt
+where "to" = '' and contract_address <> '' and %s
+group by "from"`, canonicalCond("evm_receipt"))
+
+	result, err := utils.EngineGroup[utils.TaskDB].SQL("select count(*) as count from (" + deployersSQL + ") t").
+		QueryString()
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	hits, err := strconv.ParseInt(result[0]["count"], 10, 64)
+	if err != nil {
+		log.Errorf("parse deployers count error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	var deployers []*TopDeployer
+	if err = utils.EngineGroup[utils.TaskDB].SQL(deployersSQL+`
+order by contract_count desc, address
+limit ? offset ?`, r.Limit, r.Offset).Find(&deployers); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	for index, deployer := range deployers {
+		deployer.Rank = r.Offset + 1 + index
+		deployer.FilecoinAddress = filecoinAddressOf(deployer.Address, "")
+	}
+
+	return TopDeployersList{Deployers: deployers, Hits: hits}, nil
+}
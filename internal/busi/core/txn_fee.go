@@ -0,0 +1,92 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+
+	"api-server/pkg/models/busi"
+)
+
+const (
+	txTypeLegacy     = 0
+	txTypeAccessList = 1
+)
+
+var attoFILPerFIL = big.NewInt(1e18)
+
+// formatFIL formats an attoFIL amount as an exact FIL decimal, e.g. 1500000000000000000 to 1.5.
+func formatFIL(atto *big.Int) string {
+	sign := ""
+	abs := new(big.Int).Set(atto)
+	if abs.Sign() < 0 {
+		sign, abs = "-", abs.Neg(abs)
+	}
+
+	integer, fraction := new(big.Int).QuoRem(abs, attoFILPerFIL, new(big.Int))
+	if fraction.Sign() == 0 {
+		return sign + integer.String()
+	}
+
+	decimals := strings.TrimRight(leftPad(fraction.String(), 18), "0")
+	return sign + integer.String() + "." + decimals
+}
+
+func leftPad(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return strings.Repeat("0", n-len(s)) + s
+}
+
+// effectiveGasPrice returns the receipt effective gas price, or derives it from the txn when the receipt lacks it:
+// the gas price for legacy/access list txns, min(max fee, base fee + max priority fee) for EIP-1559 ones.
+func effectiveGasPrice(txn *busi.EVMTransaction, receipt *busi.EVMReceipt, baseFee *big.Int) *big.Int {
+	if receipt != nil && receipt.EffectiveGasPrice > 0 {
+		return big.NewInt(receipt.EffectiveGasPrice)
+	}
+
+	maxFee := parseAttoFIL(txn.MaxFeePerGas)
+	if txn.Type == txTypeLegacy || txn.Type == txTypeAccessList {
+		return maxFee
+	}
+
+	price := new(big.Int).Add(baseFee, parseAttoFIL(txn.MaxPriorityFeePerGas))
+	if price.Cmp(maxFee) > 0 {
+		return maxFee
+	}
+	return price
+}
+
+// enrichTXN fills the gas, fees, timestamp and created contract of a txn from its receipt and block header, any of
+// which may be missing while the txn is pending.
+func enrichTXN(resp *EVMTransaction, receipt *busi.EVMReceipt, header *busi.EVMBlockHeader) {
+	resp.ValueFIL = formatFIL(parseAttoFIL(resp.Value))
+
+	baseFee := new(big.Int)
+	if header != nil {
+		resp.Timestamp = header.Timestamp
+		resp.BaseFeePerGas = header.BaseFeePerGas
+		baseFee = parseAttoFIL(header.BaseFeePerGas)
+	}
+
+	price := effectiveGasPrice(&resp.EVMTransaction, receipt, baseFee)
+	resp.EffectiveGasPrice = price.String()
+	if receipt == nil {
+		return
+	}
+
+	resp.GasUsed = receipt.GasUsed
+	resp.ContractAddress = receipt.ContractAddress
+
+	gasUsed := big.NewInt(receipt.GasUsed)
+	fee := new(big.Int).Mul(gasUsed, price)
+	burnt := new(big.Int).Mul(gasUsed, baseFee)
+	if burnt.Cmp(fee) > 0 {
+		burnt.Set(fee)
+	}
+	tip := new(big.Int).Sub(fee, burnt)
+
+	resp.TxnFee, resp.TxnFeeFIL = fee.String(), formatFIL(fee)
+	resp.BurntFee, resp.BurntFeeFIL = burnt.String(), formatFIL(burnt)
+	resp.MinerTip, resp.MinerTipFIL = tip.String(), formatFIL(tip)
+}
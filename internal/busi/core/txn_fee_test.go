@@ -0,0 +1,48 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseAttoFIL(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "1500000000000000000", want: "1500000000000000000"},
+		{s: "0x14d1120d7b160000", want: "1500000000000000000"},
+		{s: "0X10", want: "16"},
+		{s: "0", want: "0"},
+		{s: "", want: "0"},
+		{s: "0x", want: "0"},
+		{s: "not a number", want: "0"},
+	}
+
+	for _, tt := range tests {
+		if got := parseAttoFIL(tt.s).String(); got != tt.want {
+			t.Errorf("parseAttoFIL(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestFormatFIL(t *testing.T) {
+	tests := []struct {
+		atto string
+		want string
+	}{
+		{atto: "0", want: "0"},
+		{atto: "1", want: "0.000000000000000001"},
+		{atto: "1500000000000000000", want: "1.5"},
+		{atto: "2000000000000000000", want: "2"},
+		{atto: "-1500000000000000000", want: "-1.5"},
+		{atto: "123456789012345678901234567890", want: "123456789012.34567890123456789"},
+	}
+
+	for _, tt := range tests {
+		atto, _ := new(big.Int).SetString(tt.atto, 10)
+		if got := formatFIL(atto); got != tt.want {
+			t.Errorf("formatFIL(%s) = %s, want %s", tt.atto, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/sha3"
+	"xorm.io/builder"
+)
+
+var (
+	errInvalidTxnFilter    = errors.New("invalid txn filter")
+	errMethodNeedsContract = fmt.Errorf("%w: the method name needs a verified contract, use a selector or a signature",
+		errInvalidTxnFilter)
+)
+
+// txnFilterCondOf returns the condition of a filter, a filter that can't be resolved is a bad request.
+func txnFilterCondOf(address string, f *TxnFilter) (builder.Cond, *utils.BuErrorResponse) {
+	cond, err := txnFilterCond(address, f)
+	if errors.Is(err, errInvalidTxnFilter) {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK,
+			Response: utils.NewResponse(utils.CodeBadRequest, err.Error(), nil)}
+	}
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	return cond, nil
+}
+
+// txnFilterCond returns the condition on evm_transaction of a filter. address is the one the list belongs to, empty
+// for the txns list; a bare method name is resolved through its ABI.
+func txnFilterCond(address string, f *TxnFilter) (builder.Cond, error) {
+	cond := builder.NewCond()
+
+	if address != "" {
+		switch f.Direction {
+		case TxnDirectionIn:
+			cond = cond.And(builder.Eq{`"to"`: address}, builder.Neq{`"from"`: address})
+		case TxnDirectionOut:
+			cond = cond.And(builder.Eq{`"from"`: address}, builder.Neq{`"to"`: address})
+		case TxnDirectionSelf:
+			cond = cond.And(builder.Eq{`"from"`: address, `"to"`: address})
+		default:
+			cond = cond.And(addressTxnsCond(address))
+		}
+	}
+
+	if f.Method != "" {
+		selectors, err := methodSelectors(address, f.Method)
+		if err != nil {
+			return nil, err
+		}
+		methodCond := builder.NewCond()
+		for _, selector := range selectors {
+			// input is stored with or without 0x
+			methodCond = methodCond.Or(builder.Like{"input", "0x" + selector + "%"}, builder.Like{"input", selector + "%"})
+		}
+		cond = cond.And(methodCond)
+	}
+
+	if f.Status != "" {
+		status := TxSuccess
+		if f.Status == TxnStatusFailed {
+			status = TxFailed
+		}
+		cond = cond.And(builder.Expr(fmt.Sprintf(`exists (select 1 from evm_receipt
+  where evm_receipt.transaction_hash = evm_transaction.hash and evm_receipt.status = ? and %s)`,
+			canonicalCond("evm_receipt")), status))
+	}
+
+	// times are turned into heights, so the filter needs no join with the block headers
+	fromBlock, toBlock := f.FromBlock, f.ToBlock
+	if f.FromTime > 0 {
+		height, err := heightBefore(time.Unix(f.FromTime, 0))
+		if err != nil {
+			return nil, err
+		}
+		if height+1 > fromBlock {
+			fromBlock = height + 1
+		}
+	}
+	if f.ToTime > 0 {
+		height, err := heightBefore(time.Unix(f.ToTime+1, 0))
+		if err != nil {
+			return nil, err
+		}
+		if toBlock == 0 || height < toBlock {
+			toBlock = height
+		}
+		if toBlock < fromBlock {
+			toBlock = -1
+		}
+	}
+	if fromBlock > 0 {
+		cond = cond.And(builder.Gte{"height": fromBlock})
+	}
+	if toBlock != 0 {
+		cond = cond.And(builder.Lte{"height": toBlock})
+	}
+
+	if f.MinValue != "" {
+		cond = cond.And(builder.Expr("value::numeric >= ?::numeric", f.MinValue))
+	}
+
+	return cond, nil
+}
+
+// addressTxnsCond the condition of the txns sent or received by address.
+func addressTxnsCond(address string) builder.Cond {
+	return builder.Or(builder.Eq{`"from"`: address}, builder.Eq{`"to"`: address})
+}
+
+// methodSelectors returns the selectors (hex without 0x) of a 0x selector, a signature, or a method name of the
+// verified contract at address.
+func methodSelectors(address, method string) ([]string, error) {
+	if selectorRegexp.MatchString(method) {
+		return []string{strings.ToLower(method[2:])}, nil
+	}
+	if strings.Contains(method, "(") {
+		return []string{signatureSelector(method)}, nil
+	}
+
+	if address == "" {
+		return nil, errMethodNeedsContract
+	}
+	tokenABI, err := getContractABI(address)
+	if err != nil {
+		return nil, err
+	}
+	if tokenABI == nil {
+		return nil, errMethodNeedsContract
+	}
+
+	selectors := make([]string, 0)
+	for _, abiMethod := range tokenABI.Methods {
+		if strings.EqualFold(abiMethod.RawName, method) {
+			selectors = append(selectors, hex.EncodeToString(abiMethod.ID))
+		}
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("%w: method %s not found in the contract abi", errInvalidTxnFilter, method)
+	}
+
+	return selectors, nil
+}
+
+func signatureSelector(signature string) string {
+	return hex.EncodeToString(signatureHash(signature)[:4])
+}
+
+// signatureHash returns the keccak256 of a method or event signature, spaces ignored: the event topic0, of which
+// the method selector is the first 4 bytes.
+func signatureHash(signature string) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(strings.ReplaceAll(signature, " ", "")))
+	return hash.Sum(nil)
+}
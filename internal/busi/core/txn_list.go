@@ -0,0 +1,100 @@
+package core
+
+import (
+	"strings"
+
+	"api-server/pkg/models/busi"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// enrichTXNs decodes the methods of a page of txns and fills their status, confirmations, fees, timestamp and
+// to-is-contract flag. Receipts, block headers, contracts and ABIs are each loaded in one query whatever the page
+// size.
+func enrichTXNs(transactions []*busi.EVMTransaction) ([]*EVMTransaction, error) {
+	txns := make([]*EVMTransaction, 0, len(transactions))
+	if len(transactions) == 0 {
+		return txns, nil
+	}
+
+	hashes := make([]string, 0, len(transactions))
+	blockHashes := make([]string, 0, len(transactions))
+	tos := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		hashes = append(hashes, transaction.Hash)
+		if transaction.BlockHash != "" {
+			blockHashes = append(blockHashes, transaction.BlockHash)
+		}
+		if transaction.To != "" {
+			tos = append(tos, strings.ToLower(transaction.To))
+		}
+	}
+
+	var receipts []*busi.EVMReceipt
+	if err := canonicalSession("evm_receipt").Cols("transaction_hash", "status", "gas_used",
+		"effective_gas_price", "contract_address").In("transaction_hash", hashes).Find(&receipts); err != nil {
+		return nil, err
+	}
+	receiptOf := make(map[string]*busi.EVMReceipt, len(receipts))
+	for _, receipt := range receipts {
+		receiptOf[receipt.TransactionHash] = receipt
+	}
+
+	headerOf := make(map[string]*busi.EVMBlockHeader)
+	if len(blockHashes) > 0 {
+		var headers []*busi.EVMBlockHeader
+		if err := canonicalSession("evm_block_header").In("hash", blockHashes).Find(&headers); err != nil {
+			return nil, err
+		}
+		for _, header := range headers {
+			headerOf[header.Hash] = header
+		}
+	}
+
+	isContract := make(map[string]bool)
+	abis := make(map[string]*abi.ABI)
+	if len(tos) > 0 {
+		contracts, err := canonicalSession("evm_contract").Cols("address").In("address", tos).QueryString()
+		if err != nil {
+			return nil, err
+		}
+		for _, contract := range contracts {
+			isContract[strings.ToLower(contract["address"])] = true
+		}
+
+		if abis, err = getContractABIs(tos); err != nil {
+			return nil, err
+		}
+	}
+	abiOf := func(address string) (*abi.ABI, error) {
+		return abis[strings.ToLower(address)], nil
+	}
+
+	head, err := chainHead.get()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range transactions {
+		if transaction.To == "" {
+			transaction.MethodName = "create"
+		} else {
+			transaction.MethodName, transaction.MethodSig, transaction.Params =
+				parseMethodAndParams(transaction.Input, transaction.To, abiOf)
+		}
+
+		txn := &EVMTransaction{EVMTransaction: *transaction, ToIsContract: isContract[strings.ToLower(transaction.To)]}
+		receipt := receiptOf[transaction.Hash]
+		if receipt != nil {
+			txn.TxnStatus = int(receipt.Status)
+			txn.ConfirmationBlocks, txn.Finality = head.confirmations(transaction.Height)
+		} else {
+			txn.TxnStatus, txn.Finality = TxPending, FinalityPending
+		}
+		enrichTXN(txn, receipt, headerOf[transaction.BlockHash])
+
+		txns = append(txns, txn)
+	}
+
+	return txns, nil
+}
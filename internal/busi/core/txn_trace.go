@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api-server/pkg/models/busi"
+	"api-server/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the call types of evm_internal_tx.type. Their encoding isn't documented by the ingestion, it's assumed to number
+// call, delegatecall, staticcall, create and create2 in this order; other values are named "type N" and every call
+// keeps its raw type_code so that clients can tell.
+const (
+	CallTypeCall = iota
+	CallTypeDelegateCall
+	CallTypeStaticCall
+	CallTypeCreate
+	CallTypeCreate2
+)
+
+var callTypeNames = map[uint64]string{
+	CallTypeCall:         "call",
+	CallTypeDelegateCall: "delegatecall",
+	CallTypeStaticCall:   "staticcall",
+	CallTypeCreate:       "create",
+	CallTypeCreate2:      "create2",
+}
+
+func callTypeName(t uint64) string {
+	if name, ok := callTypeNames[t]; ok {
+		return name
+	}
+	return "type " + strconv.FormatUint(t, 10)
+}
+
+// GetTxnTrace returns the calls of a txn as a tree rooted at the txn itself. evm_internal_tx records neither the call
+// stack nor the call order, so a call is nested under the shallowest call into its caller, calls from nowhere under
+// the root, siblings ordered by hash, and the trace is flagged inferred. It has no call input either, only the txn
+// and the calls into builtin actors get method names.
+func GetTxnTrace(ctx context.Context, hash string) (interface{}, *utils.BuErrorResponse) {
+	transaction := new(busi.EVMTransaction)
+	b, err := canonicalSession("evm_transaction").Where("hash = ?", hash).Get(transaction)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if !b {
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusOK, Response: utils.ErrBlockExplorerAPIServerNotFound}
+	}
+
+	receipt := new(busi.EVMReceipt)
+	b, err = canonicalSession("evm_receipt").Cols("status", "contract_address").
+		Where("transaction_hash = ?", hash).Get(receipt)
+	if err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+	if !b {
+		receipt = nil
+	}
+
+	internalTxs := make([]*busi.EVMInternalTX, 0)
+	if err := canonicalSession("evm_internal_tx").Where("parent_hash = ?", hash).OrderBy("hash").
+		Find(&internalTxs); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	root := &TraceCall{
+		Type:  callTypeName(CallTypeCall),
+		From:  transaction.From,
+		To:    transaction.To,
+		Value: transaction.Value,
+		Calls: make([]*TraceCall, 0),
+	}
+	if transaction.To == "" {
+		root.Type, root.TypeCode, root.MethodName = callTypeName(CallTypeCreate), CallTypeCreate, "create"
+		if receipt != nil {
+			root.To = receipt.ContractAddress
+		}
+	} else {
+		root.MethodName, _, _ = parseMethodAndParamsFromContract(transaction.Input, transaction.To)
+	}
+
+	calls := make([]*TraceCall, 0, len(internalTxs))
+	for _, internalTx := range internalTxs {
+		calls = append(calls, &TraceCall{
+			Type:       callTypeName(internalTx.Type),
+			TypeCode:   internalTx.Type,
+			Hash:       internalTx.Hash,
+			From:       internalTx.From,
+			To:         internalTx.To,
+			Value:      internalTx.Value,
+			MethodName: builtinCallName(internalTx.To),
+			Calls:      make([]*TraceCall, 0),
+		})
+	}
+
+	trace := &TxnTrace{Hash: transaction.Hash, Root: root, Calls: int64(len(calls)), Inferred: true}
+	if receipt != nil {
+		trace.Status = int(receipt.Status)
+	} else {
+		trace.Status = TxPending
+	}
+
+	if err := fillTraceContractNames(append(calls, root)); err != nil {
+		log.Errorf("Execute sql error: %v", err)
+		return nil, &utils.BuErrorResponse{HttpCode: http.StatusInternalServerError,
+			Response: utils.ErrBlockExplorerAPIServerInternal}
+	}
+
+	nestCalls(root, calls)
+	for _, call := range append(calls, root) {
+		value := parseAttoFIL(call.Value)
+		call.ValueFIL, call.ValueTransfer = formatFIL(value), value.Sign() > 0
+		if call.ValueTransfer {
+			trace.ValueTransfers++
+		}
+	}
+
+	return trace, nil
+}
+
+// nestCalls nests calls breadth first: the calls from an address go under the shallowest call into it.
+func nestCalls(root *TraceCall, calls []*TraceCall) {
+	nested := make([]bool, len(calls))
+	queue := []*TraceCall{root}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for i, call := range calls {
+			if nested[i] || !strings.EqualFold(call.From, parent.To) {
+				continue
+			}
+			nested[i] = true
+			call.Depth = parent.Depth + 1
+			parent.Calls = append(parent.Calls, call)
+			queue = append(queue, call)
+		}
+	}
+
+	for i, call := range calls {
+		if !nested[i] {
+			call.Depth = 1
+			root.Calls = append(root.Calls, call)
+		}
+	}
+}
+
+// fillTraceContractNames names the callees that are verified contracts.
+func fillTraceContractNames(calls []*TraceCall) error {
+	addresses := make([]interface{}, 0, len(calls))
+	for _, call := range calls {
+		if call.To != "" {
+			addresses = append(addresses, strings.ToLower(call.To))
+		}
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	var contractVerifies []*busi.EVMContractVerify
+	if err := utils.EngineGroup[utils.APIDB].Cols("address", "contract_name").
+		Where("status = ?", busi.EVMContractVerifyStatusSuccessfully).In("address", addresses...).
+		Find(&contractVerifies); err != nil {
+		return err
+	}
+	names := make(map[string]string, len(contractVerifies))
+	for _, cv := range contractVerifies {
+		names[strings.ToLower(cv.Address)] = cv.ContractName
+	}
+
+	for _, call := range calls {
+		call.ContractName = names[strings.ToLower(call.To)]
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNestCalls(t *testing.T) {
+	tests := []struct {
+		name string
+		// calls from -> to, by hash
+		calls [][3]string
+		// want the hashes of the children of every caller, "" for the root, and the depths
+		want       map[string][]string
+		wantDepths map[string]int
+	}{
+		{
+			name:       "chain",
+			calls:      [][3]string{{"c1", "0xa", "0xb"}, {"c2", "0xb", "0xc"}},
+			want:       map[string][]string{"": {"c1"}, "c1": {"c2"}, "c2": nil},
+			wantDepths: map[string]int{"c1": 1, "c2": 2},
+		},
+		{
+			name:       "siblings",
+			calls:      [][3]string{{"c1", "0xa", "0xb"}, {"c2", "0xa", "0xc"}},
+			want:       map[string][]string{"": {"c1", "c2"}, "c1": nil, "c2": nil},
+			wantDepths: map[string]int{"c1": 1, "c2": 1},
+		},
+		{
+			name: "under the shallowest call into the caller",
+			calls: [][3]string{{"c1", "0xa", "0xb"}, {"c2", "0xb", "0xc"}, {"c3", "0xc", "0xb"},
+				{"c4", "0xb", "0xd"}},
+			want:       map[string][]string{"": {"c1"}, "c1": {"c2", "c4"}, "c2": {"c3"}, "c3": nil, "c4": nil},
+			wantDepths: map[string]int{"c1": 1, "c2": 2, "c3": 3, "c4": 2},
+		},
+		{
+			name:       "from nowhere under the root",
+			calls:      [][3]string{{"c1", "0xa", "0xb"}, {"c2", "0xe", "0xf"}},
+			want:       map[string][]string{"": {"c1", "c2"}, "c1": nil, "c2": nil},
+			wantDepths: map[string]int{"c1": 1, "c2": 1},
+		},
+		{
+			name:       "case insensitive",
+			calls:      [][3]string{{"c1", "0xA", "0xB"}, {"c2", "0xb", "0xc"}},
+			want:       map[string][]string{"": {"c1"}, "c1": {"c2"}, "c2": nil},
+			wantDepths: map[string]int{"c1": 1, "c2": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &TraceCall{From: "0xsender", To: "0xa"}
+			calls := make([]*TraceCall, 0, len(tt.calls))
+			for _, c := range tt.calls {
+				calls = append(calls, &TraceCall{Hash: c[0], From: c[1], To: c[2]})
+			}
+
+			nestCalls(root, calls)
+
+			for _, call := range append(calls, root) {
+				var children []string
+				for _, child := range call.Calls {
+					children = append(children, child.Hash)
+				}
+				if !reflect.DeepEqual(children, tt.want[call.Hash]) {
+					t.Errorf("calls of %q = %v, want %v", call.Hash, children, tt.want[call.Hash])
+				}
+				if call != root && call.Depth != tt.wantDepths[call.Hash] {
+					t.Errorf("depth of %s = %d, want %d", call.Hash, call.Depth, tt.wantDepths[call.Hash])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+-- Indexes backing the api queries on the ingested task_db tables. task_db is owned by the ingestion, the api server
+-- doesn't run DDL against it: apply this with psql, outside of a transaction, as "create index concurrently" can't
+-- run in one. The builds don't block the ingestion writes; rerun it if a build fails, it leaves an invalid index
+-- which "if not exists" skips, so drop that one first.
+
+-- search prefix autocomplete
+create index concurrently if not exists evm_address_address_prefix on evm_address (address text_pattern_ops);
+create index concurrently if not exists evm_contract_address_prefix on evm_contract (address text_pattern_ops);
+create index concurrently if not exists evm_transaction_hash_prefix on evm_transaction (hash text_pattern_ops);
+create index concurrently if not exists evm_block_header_hash_prefix on evm_block_header (hash text_pattern_ops);
+
+-- rich list and deployer leaderboard
+create index concurrently if not exists evm_address_balance on evm_address ((balance::numeric) desc);
+create index concurrently if not exists evm_contract_balance on evm_contract ((balance::numeric) desc);
+create index concurrently if not exists evm_receipt_contract_creation on evm_receipt ("from") where "to" = '';
@@ -55,6 +55,9 @@ type EVMInternalTX struct {
 	From       string `json:"from"`
 	To         string `json:"to"`
 	Value      string `json:"value"`
+
+	MethodName string `xorm:"-" json:"method_name"`
+	TypeName   string `xorm:"-" json:"type_name"`
 }
 
 func (i *EVMInternalTX) TableName() string {
@@ -159,6 +162,107 @@ func (a *EVMAddress) TableName() string {
 	return "evm_address"
 }
 
+// EVMAddressLabel public name tag of an address, loaded by ops, see README
+type EVMAddressLabel struct {
+	ID        int64     `xorm:"pk autoincr" json:"id"`
+	Address   string    `xorm:"varchar(255) notnull default '' index" json:"address"`
+	Label     string    `xorm:"varchar(255) notnull default ''" json:"label"`
+	Category  string    `xorm:"varchar(100) notnull default ''" json:"category"`
+	CreateAt  time.Time `xorm:"created" json:"create_at"`
+	UpdatedAt time.Time `xorm:"updated" json:"updated_at"`
+}
+
+func (l *EVMAddressLabel) TableName() string {
+	return "evm_address_label"
+}
+
+// EVMLog a log of a canonical receipt, indexed from evm_receipt.logs by the log indexer
+type EVMLog struct {
+	Height int64 `xorm:"bigint notnull pk" json:"height"`
+	// Position the index of the log in its block, by txn index then log index
+	Position         int64  `xorm:"bigint notnull pk" json:"position"`
+	LogIndex         int64  `xorm:"bigint notnull default 0" json:"log_index"`
+	TransactionHash  string `xorm:"varchar(255) notnull default '' index" json:"transaction_hash"`
+	TransactionIndex int64  `xorm:"bigint notnull default 0" json:"transaction_index"`
+	BlockHash        string `xorm:"varchar(255) notnull default ''" json:"block_hash"`
+	BlockNumber      int64  `xorm:"bigint notnull default 0" json:"block_number"`
+	Address          string `xorm:"varchar(255) notnull default ''" json:"address"`
+	Topic0           string `xorm:"varchar(66) notnull default ''" json:"topic0"`
+	Topic1           string `xorm:"varchar(66) notnull default ''" json:"topic1"`
+	Topic2           string `xorm:"varchar(66) notnull default ''" json:"topic2"`
+	Topic3           string `xorm:"varchar(66) notnull default ''" json:"topic3"`
+	Data             string `xorm:"text notnull default ''" json:"data"`
+}
+
+func (l *EVMLog) TableName() string {
+	return "evm_log"
+}
+
+// EVMLogHeight the fingerprint of the canonical receipts with logs of a height as the log indexer indexed them, kept
+// for the heights that may still change so that only the changed ones are indexed again
+type EVMLogHeight struct {
+	Height       int64  `xorm:"bigint notnull pk" json:"height"`
+	ReceiptsHash string `xorm:"varchar(32) notnull default ''" json:"receipts_hash"`
+}
+
+func (h *EVMLogHeight) TableName() string {
+	return "evm_log_height"
+}
+
+// EVMAddressSummary the activity of an address over the finalized heights, maintained by the address summarizer
+type EVMAddressSummary struct {
+	Address          string    `xorm:"varchar(255) notnull pk" json:"address"`
+	TxnCount         int64     `xorm:"bigint notnull default 0" json:"txn_count"`
+	InternalTxnCount int64     `xorm:"bigint notnull default 0" json:"internal_txn_count"`
+	FirstSeenHeight  int64     `xorm:"bigint notnull default 0" json:"first_seen_height"`
+	FirstSeenTime    int64     `xorm:"bigint notnull default 0" json:"first_seen_time"`
+	LastSeenHeight   int64     `xorm:"bigint notnull default 0" json:"last_seen_height"`
+	LastSeenTime     int64     `xorm:"bigint notnull default 0" json:"last_seen_time"`
+	Creator          string    `xorm:"varchar(255) notnull default ''" json:"creator"`
+	CreationTxn      string    `xorm:"varchar(255) notnull default ''" json:"creation_txn"`
+	UpdatedAt        time.Time `xorm:"updated" json:"updated_at"`
+}
+
+func (s *EVMAddressSummary) TableName() string {
+	return "evm_address_summary"
+}
+
+// EVMTokenHolding the balance of a token held by an address, summed from the finalized Transfer logs
+type EVMTokenHolding struct {
+	Address   string `xorm:"varchar(255) notnull pk" json:"address"`
+	Token     string `xorm:"varchar(255) notnull pk" json:"token"`
+	TokenType string `xorm:"varchar(20) notnull default ''" json:"token_type"`
+	Balance   string `xorm:"numeric(78) notnull default 0" json:"balance"`
+}
+
+func (h *EVMTokenHolding) TableName() string {
+	return "evm_token_holding"
+}
+
+// EVMSyncState the height up to which a background indexer has processed the chain
+type EVMSyncState struct {
+	Name      string    `xorm:"varchar(100) notnull pk" json:"name"`
+	Height    int64     `xorm:"bigint notnull default 0" json:"height"`
+	UpdatedAt time.Time `xorm:"updated" json:"updated_at"`
+}
+
+func (s *EVMSyncState) TableName() string {
+	return "evm_sync_state"
+}
+
+// EVMContractMetricsDaily the task_db metrics of a contract accumulated up to the end of a stat date, for the
+// contracts of fvm_contract_summary_daily, maintained by the contract metrics aggregator
+type EVMContractMetricsDaily struct {
+	StatDate        time.Time `xorm:"date notnull pk" json:"stat_date"`
+	ContractAddress string    `xorm:"varchar(255) notnull pk" json:"contract_address"`
+	InternalTxns    int64     `xorm:"bigint notnull default 0" json:"internal_txns"`
+	FilBurned       string    `xorm:"numeric(78) notnull default 0" json:"fil_burned"`
+}
+
+func (m *EVMContractMetricsDaily) TableName() string {
+	return "evm_contract_metrics_daily"
+}
+
 type FVMSummaryDaily struct {
 	StatDate                  time.Time
 	TotalContractCount        int64
@@ -2,8 +2,32 @@ package busi
 
 var (
 	Tables []interface{}
+
+	// APIDBIndexes indexes xorm tags can't express, created on start. The task_db ones are in migrations/task_db.
+	APIDBIndexes []string
 )
 
 func init() {
 	Tables = append(Tables, new(EVMContractVerify))
+	Tables = append(Tables, new(EVMAddressLabel))
+	Tables = append(Tables, new(EVMLog))
+	Tables = append(Tables, new(EVMLogHeight))
+	Tables = append(Tables, new(EVMAddressSummary))
+	Tables = append(Tables, new(EVMTokenHolding))
+	Tables = append(Tables, new(EVMSyncState))
+	Tables = append(Tables, new(EVMContractMetricsDaily))
+
+	// prefix autocomplete
+	APIDBIndexes = append(APIDBIndexes,
+		"create index if not exists evm_contract_verify_contract_name_prefix on evm_contract_verify (lower(contract_name) text_pattern_ops)",
+		"create index if not exists evm_address_label_label_prefix on evm_address_label (lower(label) text_pattern_ops)",
+	)
+	// log filters, in the (height, position) order of their cursors
+	APIDBIndexes = append(APIDBIndexes,
+		"create index if not exists evm_log_address on evm_log (address, height, position)",
+		"create index if not exists evm_log_topic0 on evm_log (topic0, height, position)",
+		"create index if not exists evm_log_topic1 on evm_log (topic1, height, position) where topic1 <> ''",
+		"create index if not exists evm_log_topic2 on evm_log (topic2, height, position) where topic2 <> ''",
+		"create index if not exists evm_log_topic3 on evm_log (topic3, height, position) where topic3 <> ''",
+	)
 }
@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Filecoin address protocols, see https://spec.filecoin.io/appendix/address/
+const (
+	AddressProtocolID        byte = 0
+	AddressProtocolSecp256k1 byte = 1
+	AddressProtocolActor     byte = 2
+	AddressProtocolBLS       byte = 3
+	AddressProtocolDelegated byte = 4
+)
+
+const (
+	NetworkMainnet byte = 'f'
+	NetworkTestnet byte = 't'
+
+	// EAMActorID the Ethereum Address Manager namespace of f410 addresses
+	EAMActorID uint64 = 10
+
+	addressChecksumLength = 4
+	ethAddressLength      = 20
+	// the payload of f1/f2 addresses is a blake2b-160 hash, of f3 addresses a BLS public key
+	addressPayloadHashLength = 20
+	blsPublicKeyLength       = 48
+)
+
+var (
+	addressEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+	ErrInvalidAddress         = errors.New("invalid address")
+	ErrInvalidAddressChecksum = errors.New("invalid address checksum")
+)
+
+// Address an address normalized from any of its accepted forms (0x, f410/t410, f0/t0, f1/f2/f3).
+type Address struct {
+	// Raw the address as given by the caller
+	Raw string
+	// EthAddress lowercase 0x form, the form stored in task_db. Empty for f1/f2/f3 addresses.
+	EthAddress string
+	// FilecoinAddress the f410 (or f0 for masked ID addresses) form
+	FilecoinAddress string
+	// ActorID set when the address was given as an ID address
+	ActorID *uint64
+}
+
+// QueryValues returns the values that may identify the address in `address` or `filecoin_address` columns.
+func (a *Address) QueryValues() []string {
+	values := make([]string, 0, 3)
+	for _, v := range []string{a.EthAddress, a.FilecoinAddress, strings.ToLower(a.Raw)} {
+		if v == "" {
+			continue
+		}
+		exist := false
+		for _, value := range values {
+			if value == v {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// NetworkPrefix returns the address network prefix of the configured network.
+func NetworkPrefix() byte {
+	switch strings.ToLower(CNF.APIServer.Network) {
+	case "", "mainnet":
+		return NetworkMainnet
+	default:
+		return NetworkTestnet
+	}
+}
+
+// NormalizeAddress parses an 0x or filecoin address and fills in both forms.
+// Mixed-case 0x addresses must carry a valid EIP-55 checksum.
+func NormalizeAddress(s string) (*Address, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrInvalidAddress
+	}
+
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		eth, err := ParseEthAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		address := &Address{Raw: s, EthAddress: strings.ToLower(eth.Hex())}
+		if id, ok := MaskedIDFromEthAddress(eth); ok {
+			address.ActorID = &id
+		}
+		address.FilecoinAddress, err = EthAddressToFilecoin(eth, NetworkPrefix())
+		if err != nil {
+			return nil, err
+		}
+		return address, nil
+	}
+
+	network, protocol, payload, err := decodeFilecoinAddress(s)
+	if err != nil {
+		return nil, err
+	}
+
+	address := &Address{Raw: s, FilecoinAddress: strings.ToLower(s)}
+	switch protocol {
+	case AddressProtocolID:
+		id, _ := strconv.ParseUint(payload.id, 10, 64)
+		address.ActorID = &id
+		address.FilecoinAddress = fmt.Sprintf("%c0%d", network, id)
+		address.EthAddress = strings.ToLower(MaskedIDToEthAddress(id).Hex())
+	case AddressProtocolDelegated:
+		if payload.namespace == EAMActorID && len(payload.subAddress) == ethAddressLength {
+			address.EthAddress = strings.ToLower(ethcommon.BytesToAddress(payload.subAddress).Hex())
+		}
+	}
+
+	return address, nil
+}
+
+// ParseEthAddress parses a 0x address, verifying the EIP-55 checksum of mixed-case input.
+func ParseEthAddress(s string) (ethcommon.Address, error) {
+	if !ethcommon.IsHexAddress(s) || !(strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")) {
+		return ethcommon.Address{}, ErrInvalidAddress
+	}
+
+	eth := ethcommon.HexToAddress(s)
+	hexPart := s[2:]
+	if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) && eth.Hex()[2:] != hexPart {
+		return ethcommon.Address{}, ErrInvalidAddressChecksum
+	}
+
+	return eth, nil
+}
+
+// EthAddressToFilecoin converts an 0x address to its filecoin form: f0 for masked ID addresses, f410 otherwise.
+func EthAddressToFilecoin(eth ethcommon.Address, network byte) (string, error) {
+	if id, ok := MaskedIDFromEthAddress(eth); ok {
+		return fmt.Sprintf("%c0%d", network, id), nil
+	}
+	return DelegatedAddress(EAMActorID, eth.Bytes(), network)
+}
+
+// DelegatedAddress encodes an f4 address of namespace with the given sub-address.
+func DelegatedAddress(namespace uint64, subAddress []byte, network byte) (string, error) {
+	if network != NetworkMainnet && network != NetworkTestnet {
+		return "", ErrInvalidAddress
+	}
+
+	payload := append(uvarint(namespace), subAddress...)
+	checksum := addressChecksum(AddressProtocolDelegated, payload)
+
+	return fmt.Sprintf("%c%d%df%s", network, AddressProtocolDelegated, namespace,
+		addressEncoding.EncodeToString(append(append([]byte{}, subAddress...), checksum...))), nil
+}
+
+// MaskedIDToEthAddress returns the 0xff000...<id> address of an actor ID.
+func MaskedIDToEthAddress(id uint64) ethcommon.Address {
+	var eth ethcommon.Address
+	eth[0] = 0xff
+	binary.BigEndian.PutUint64(eth[12:], id)
+	return eth
+}
+
+// MaskedIDFromEthAddress returns the actor ID if eth is a masked ID address.
+func MaskedIDFromEthAddress(eth ethcommon.Address) (uint64, bool) {
+	if eth[0] != 0xff {
+		return 0, false
+	}
+	for _, b := range eth[1:12] {
+		if b != 0 {
+			return 0, false
+		}
+	}
+	return binary.BigEndian.Uint64(eth[12:]), true
+}
+
+type filecoinAddressPayload struct {
+	id         string
+	namespace  uint64
+	subAddress []byte
+}
+
+func decodeFilecoinAddress(s string) (byte, byte, *filecoinAddressPayload, error) {
+	s = strings.ToLower(s)
+	if len(s) < 3 {
+		return 0, 0, nil, ErrInvalidAddress
+	}
+
+	network := s[0]
+	if network != NetworkMainnet && network != NetworkTestnet {
+		return 0, 0, nil, ErrInvalidAddress
+	}
+	if s[1] < '0' || s[1] > '4' {
+		return 0, 0, nil, ErrInvalidAddress
+	}
+	protocol := s[1] - '0'
+	raw := s[2:]
+
+	var payload filecoinAddressPayload
+	switch protocol {
+	case AddressProtocolID:
+		if _, err := strconv.ParseUint(raw, 10, 64); err != nil {
+			return 0, 0, nil, ErrInvalidAddress
+		}
+		payload.id = raw
+		return network, protocol, &payload, nil
+	case AddressProtocolDelegated:
+		parts := strings.SplitN(raw, "f", 2)
+		if len(parts) != 2 {
+			return 0, 0, nil, ErrInvalidAddress
+		}
+		namespace, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return 0, 0, nil, ErrInvalidAddress
+		}
+		payload.namespace = namespace
+		raw = parts[1]
+	}
+
+	// the unused bits of the last character must be zero, an address has a single encoding
+	decoded, err := addressEncoding.DecodeString(raw)
+	if err != nil || len(decoded) <= addressChecksumLength || addressEncoding.EncodeToString(decoded) != raw {
+		return 0, 0, nil, ErrInvalidAddress
+	}
+	body, checksum := decoded[:len(decoded)-addressChecksumLength], decoded[len(decoded)-addressChecksumLength:]
+	switch protocol {
+	case AddressProtocolSecp256k1, AddressProtocolActor:
+		if len(body) != addressPayloadHashLength {
+			return 0, 0, nil, ErrInvalidAddress
+		}
+	case AddressProtocolBLS:
+		if len(body) != blsPublicKeyLength {
+			return 0, 0, nil, ErrInvalidAddress
+		}
+	}
+
+	checksumPayload := body
+	if protocol == AddressProtocolDelegated {
+		checksumPayload = append(uvarint(payload.namespace), body...)
+		payload.subAddress = body
+	}
+	if string(addressChecksum(protocol, checksumPayload)) != string(checksum) {
+		return 0, 0, nil, ErrInvalidAddressChecksum
+	}
+
+	return network, protocol, &payload, nil
+}
+
+func addressChecksum(protocol byte, payload []byte) []byte {
+	h, _ := blake2b.New(addressChecksumLength, nil)
+	h.Write([]byte{protocol})
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+func uvarint(v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return buf[:binary.PutUvarint(buf, v)]
+}
+
+// FilecoinAddressFromBytes encodes a filecoin address from its byte representation (protocol || payload).
+func FilecoinAddressFromBytes(b []byte, network byte) (string, error) {
+	if len(b) < 2 {
+		return "", ErrInvalidAddress
+	}
+
+	protocol, payload := b[0], b[1:]
+	switch protocol {
+	case AddressProtocolID:
+		id, n := binary.Uvarint(payload)
+		if n <= 0 || n != len(payload) {
+			return "", ErrInvalidAddress
+		}
+		return fmt.Sprintf("%c0%d", network, id), nil
+	case AddressProtocolSecp256k1, AddressProtocolActor, AddressProtocolBLS:
+		checksum := addressChecksum(protocol, payload)
+		return fmt.Sprintf("%c%d%s", network, protocol,
+			addressEncoding.EncodeToString(append(append([]byte{}, payload...), checksum...))), nil
+	case AddressProtocolDelegated:
+		namespace, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return "", ErrInvalidAddress
+		}
+		return DelegatedAddress(namespace, payload[n:], network)
+	}
+
+	return "", ErrInvalidAddress
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseEthAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		wantErr error
+	}{
+		{name: "checksummed", s: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{name: "lowercase", s: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{name: "uppercase", s: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"},
+		{name: "bad checksum", s: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", wantErr: ErrInvalidAddressChecksum},
+		{name: "too short", s: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", wantErr: ErrInvalidAddress},
+		{name: "no prefix", s: "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", wantErr: ErrInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseEthAddress(tt.s); err != tt.wantErr {
+				t.Errorf("ParseEthAddress(%s) err = %v, want %v", tt.s, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		s            string
+		wantEth      string
+		wantFilecoin string
+		wantErr      error
+	}{
+		{name: "0x", s: "0x52963EF50e27e06D72D59fcB4F3c2a687BE3cfEf",
+			wantEth:      "0x52963ef50e27e06d72d59fcb4f3c2a687be3cfef",
+			wantFilecoin: "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa"},
+		{name: "f410", s: "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa",
+			wantEth:      "0x52963ef50e27e06d72d59fcb4f3c2a687be3cfef",
+			wantFilecoin: "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa"},
+		{name: "masked id", s: "0xff00000000000000000000000000000000000064",
+			wantEth: "0xff00000000000000000000000000000000000064", wantFilecoin: "f0100"},
+		{name: "id", s: "f0100", wantEth: "0xff00000000000000000000000000000000000064", wantFilecoin: "f0100"},
+		{name: "f1", s: "f17uoq6tp427uzv7fztkbsnn64iwotfrristwpryy",
+			wantFilecoin: "f17uoq6tp427uzv7fztkbsnn64iwotfrristwpryy"},
+		{name: "f410 bad checksum", s: "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamya",
+			wantErr: ErrInvalidAddressChecksum},
+		{name: "f1 bad checksum", s: "f17uoq6tp427uzv7fztkbsnn64iwotfrristwprya", wantErr: ErrInvalidAddressChecksum},
+		{name: "non-canonical trailing bits", s: "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxb",
+			wantErr: ErrInvalidAddress},
+		{name: "f3", s: "f3vvmn62lofvhjd2ugzca6sof2j2ubwok6cj4xxbfzz4yuxfkgobpihhd2thlanmsh3w2ptld2gqkn2jvlss4a",
+			wantFilecoin: "f3vvmn62lofvhjd2ugzca6sof2j2ubwok6cj4xxbfzz4yuxfkgobpihhd2thlanmsh3w2ptld2gqkn2jvlss4a"},
+		{name: "f1 short payload", s: "f1aaaqeayeaudaocajbifqydiob4ibcewmr6jsk", wantErr: ErrInvalidAddress},
+		{name: "f3 short payload", s: "f3aaaqeayeaudaocajbifqydiob4ibceqt6r3ej7a", wantErr: ErrInvalidAddress},
+		{name: "unknown network", s: "x0100", wantErr: ErrInvalidAddress},
+		{name: "unknown protocol", s: "f5100", wantErr: ErrInvalidAddress},
+		{name: "empty", s: " ", wantErr: ErrInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := NormalizeAddress(tt.s)
+			if err != tt.wantErr {
+				t.Fatalf("NormalizeAddress(%s) err = %v, want %v", tt.s, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if address.EthAddress != tt.wantEth || address.FilecoinAddress != tt.wantFilecoin {
+				t.Errorf("NormalizeAddress(%s) = %s, %s, want %s, %s", tt.s, address.EthAddress,
+					address.FilecoinAddress, tt.wantEth, tt.wantFilecoin)
+			}
+		})
+	}
+}
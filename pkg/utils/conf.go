@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"syscall"
 
 	"github.com/jinzhu/configor"
@@ -18,6 +19,27 @@ type APIServer struct {
 	DB     string `toml:"task_db"`
 	BusiDB string `toml:"api_db"`
 	StatDB string `toml:"stat_db"`
+
+	// Network mainnet or calibnet, decides the f/t prefix of filecoin addresses in responses
+	Network string `toml:"network" default:"mainnet"`
+
+	// FinalityDepth epochs after which a block is final, 900 on filecoin
+	FinalityDepth int64 `toml:"finality_depth" default:"900"`
+	// ChainHeadRefreshInterval seconds between two refreshes of the in memory chain head
+	ChainHeadRefreshInterval int `toml:"chain_head_refresh_interval" default:"30"`
+	// LogIndexInterval seconds between two passes of the log indexer filling evm_log, followed by the address summaries
+	LogIndexInterval int `toml:"log_index_interval" default:"30"`
+
+	// MaxChainHeadLag seconds the task_db chain head may lag behind wall clock before the server is not ready
+	MaxChainHeadLag int64 `toml:"max_chain_head_lag" default:"600"`
+	// MaxStatDateLag days the stat tables may lag behind today (UTC) before the server is not ready
+	MaxStatDateLag int `toml:"max_stat_date_lag" default:"2"`
+
+	// AdminToken the X-Admin-Token of the /debug routes, they are disabled when empty
+	AdminToken string `toml:"admin_token"`
+
+	// ExportMaxRows the most rows an export streams
+	ExportMaxRows int `toml:"export_max_rows" default:"100000"`
 }
 
 func InitConfFile(file string, cf *TomlConfig) error {
@@ -30,5 +52,30 @@ func InitConfFile(file string, cf *TomlConfig) error {
 		return err
 	}
 
+	return cf.APIServer.validate()
+}
+
+// validate rejects the settings the server can't run with, e.g. tickers panic on non positive intervals.
+func (s *APIServer) validate() error {
+	if s.ChainHeadRefreshInterval <= 0 {
+		return errors.New("chain_head_refresh_interval should be greater than 0")
+	}
+	if s.LogIndexInterval <= 0 {
+		return errors.New("log_index_interval should be greater than 0")
+	}
+	// a non positive depth reports every included txn as finalized at once
+	if s.FinalityDepth <= 0 {
+		return errors.New("finality_depth should be greater than 0")
+	}
+	if s.MaxChainHeadLag < 0 {
+		return errors.New("max_chain_head_lag should not be negative")
+	}
+	if s.MaxStatDateLag < 0 {
+		return errors.New("max_stat_date_lag should not be negative")
+	}
+	if s.ExportMaxRows <= 0 {
+		return errors.New("export_max_rows should be greater than 0")
+	}
+
 	return nil
 }
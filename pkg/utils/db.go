@@ -27,9 +27,10 @@ var (
 )
 
 type EngineInfo struct {
-	Key    string
-	Schema string
-	Tables []interface{}
+	Key     string
+	Schema  string
+	Tables  []interface{}
+	Indexes []string
 }
 
 func NewEngineGroup(ctx context.Context, ei *[]EngineInfo) map[string]*xorm.Engine {
@@ -37,6 +38,7 @@ func NewEngineGroup(ctx context.Context, ei *[]EngineInfo) map[string]*xorm.Engi
 
 	for _, sei := range *ei {
 		x, _ := InitDBEngine(ctx, sei.Schema, sei.Tables)
+		CreateIndexes(x, sei.Indexes)
 		engineGroup[sei.Key] = x
 	}
 
@@ -82,6 +84,18 @@ func SyncTables(x *xorm.Engine, tables []interface{}) error {
 	return x.StoreEngine("InnoDB").Sync2(tables...)
 }
 
+// CreateIndexes creates the indexes the ORM can't sync, a failure (e.g. a read only user) is only logged.
+func CreateIndexes(x *xorm.Engine, indexes []string) {
+	if x == nil {
+		return
+	}
+	for _, index := range indexes {
+		if _, err := x.Exec(index); err != nil {
+			log.Warnf("create index error: %v, sql: %s", err, index)
+		}
+	}
+}
+
 func newEngine(ctx context.Context /*, migrateFunc func(*xorm.Engine) error*/, schema string) (x *xorm.Engine,
 	err error) {
 	if x, err = setEngine(schema); err != nil {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"crypto/subtle"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -32,3 +33,20 @@ func Cors() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminAuth guards the admin routes with the token of the X-Admin-Token header, they don't exist when no token is
+// configured.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusNotFound, ErrNotFound)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+
+		c.Next()
+	}
+}
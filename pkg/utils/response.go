@@ -76,12 +76,14 @@ const (
 	CodeNotFound
 	CodeUserNotFound
 	CodeForbidSendEmail
+	CodeUnauthorized
 
 	CodeBlockExplorerAPIServer            = 50000
 	CodeBlockExplorerAPIServerParamsErr   = 50001
 	CodeBlockExplorerAPIServerInternalErr = 50002
 	CodeBlockExplorerAPIServerNotFoundErr = 50003
 	CodeContractVerified                  = 50004
+	CodeBlockExplorerAPIServerNotReadyErr = 50005
 )
 
 var (
@@ -91,6 +93,7 @@ var (
 	ErrInternalServer = &Response{Code: CodeInternalServer, Message: "server internal error."}
 	ErrBadRequest     = &Response{Code: CodeBadRequest, Message: "bad request."}
 	ErrNotFound       = &Response{Code: CodeNotFound, Message: "object not found."}
+	ErrUnauthorized   = &Response{Code: CodeUnauthorized, Message: "unauthorized."}
 
 	ErrBlockExplorerAPIServerParams   = &Response{Code: CodeBlockExplorerAPIServerParamsErr, Message: "API parameters error."}
 	ErrBlockExplorerAPIServerInternal = &Response{Code: CodeBlockExplorerAPIServerInternalErr, Message: "Internal server error."}